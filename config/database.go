@@ -23,7 +23,7 @@ func ConnectDatabase() {
 		panic(err)
 	}
 
-	err = database.AutoMigrate(&models.User{}, &models.Address{}, &models.Order{}, &models.Service{})
+	err = database.AutoMigrate(&models.User{}, &models.Address{}, &models.Order{}, &models.Service{}, &models.OrderStatusEvent{}, &models.Session{}, &models.StaffInvite{}, &models.ServicePriceHistory{}, &models.ServiceAddon{}, &models.OrderAddon{}, &models.OrderCharge{}, &models.OrderItem{}, &models.Promo{}, &models.PromoRedemption{}, &models.Payment{}, &models.ReconciliationReport{}, &models.Refund{}, &models.TimeSlot{}, &models.CourierArea{}, &models.CourierLocation{}, &models.ServiceArea{}, &models.DeliveryZone{}, &models.OrderProof{}, &models.Garment{}, &models.GarmentScan{}, &models.ProcessingStage{}, &models.OrderStageEvent{})
 	if err != nil {
 		log.Fatal("Failed to migrate database:", err)
 	}
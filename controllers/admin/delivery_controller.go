@@ -0,0 +1,37 @@
+package admin_controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raihansyahrin/backend_laundry_app.git/config"
+	"github.com/raihansyahrin/backend_laundry_app.git/lifecycle"
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+)
+
+// OverrideDelivery marks an order delivered without the customer's code,
+// for example when the customer cannot be reached. The reason is required
+// and kept in the order history.
+func OverrideDelivery(c *gin.Context) {
+	var body struct {
+		Reason string `json:"reason" form:"reason"`
+	}
+	if err := c.ShouldBind(&body); err != nil || strings.TrimSpace(body.Reason) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "A reason is required to confirm a delivery without the customer's code"})
+		return
+	}
+
+	var order models.Order
+	if err := config.DB.First(&order, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Order not found"})
+		return
+	}
+
+	if err := lifecycle.OverrideDelivery(config.DB, &order, strings.TrimSpace(body.Reason), lifecycle.ActorFromContext(c)); err != nil {
+		c.JSON(lifecycle.ErrorStatus(err), gin.H{"message": "Failed to confirm delivery", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Order marked as delivered"})
+}
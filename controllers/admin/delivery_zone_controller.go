@@ -0,0 +1,121 @@
+package admin_controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raihansyahrin/backend_laundry_app.git/config"
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+)
+
+type DeliveryZoneController struct{}
+
+// GetDeliveryZones mengambil semua zona ongkir
+func (dc *DeliveryZoneController) GetDeliveryZones(c *gin.Context) {
+	var zones []models.DeliveryZone
+	if err := config.DB.Order("area, district, sub_district").Find(&zones).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve delivery zones"})
+		return
+	}
+
+	data := []gin.H{}
+	for _, zone := range zones {
+		data = append(data, deliveryZoneData(zone))
+	}
+	c.JSON(http.StatusOK, gin.H{"data": data, "code": 200, "success": true})
+}
+
+// CreateDeliveryZone membuat zona ongkir baru
+func (dc *DeliveryZoneController) CreateDeliveryZone(c *gin.Context) {
+	var zone models.DeliveryZone
+	if !bindDeliveryZone(c, &zone) {
+		return
+	}
+
+	if err := config.DB.Create(&zone).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to create delivery zone"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"success": true,
+		"message": "Delivery zone created successfully",
+		"data":    deliveryZoneData(zone),
+	})
+}
+
+// UpdateDeliveryZone mengupdate zona ongkir berdasarkan ID
+func (dc *DeliveryZoneController) UpdateDeliveryZone(c *gin.Context) {
+	var zone models.DeliveryZone
+	if err := config.DB.First(&zone, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Delivery zone not found"})
+		return
+	}
+	if !bindDeliveryZone(c, &zone) {
+		return
+	}
+
+	if err := config.DB.Save(&zone).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to update delivery zone"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"success": true,
+		"message": "Delivery zone updated successfully",
+		"data":    deliveryZoneData(zone),
+	})
+}
+
+// DeleteDeliveryZone menghapus zona ongkir berdasarkan ID
+func (dc *DeliveryZoneController) DeleteDeliveryZone(c *gin.Context) {
+	if err := config.DB.Delete(&models.DeliveryZone{}, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to delete delivery zone"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Delivery zone deleted successfully"})
+}
+
+// bindDeliveryZone validates the request body and copies it onto zone. It
+// answers the request itself and returns false when the body is invalid.
+func bindDeliveryZone(c *gin.Context, zone *models.DeliveryZone) bool {
+	var input models.DeliveryZone
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input format"})
+		return false
+	}
+
+	zone.Area = strings.TrimSpace(input.Area)
+	zone.District = strings.TrimSpace(input.District)
+	zone.SubDistrict = strings.TrimSpace(input.SubDistrict)
+	zone.Fee = input.Fee
+	zone.FreeAbove = input.FreeAbove
+	if zone.Area == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Area is required"})
+		return false
+	}
+	if zone.SubDistrict != "" && zone.District == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "District is required when a sub district is given"})
+		return false
+	}
+	if zone.Fee < 0 || zone.FreeAbove < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Fee and free delivery threshold cannot be negative"})
+		return false
+	}
+	return true
+}
+
+func deliveryZoneData(zone models.DeliveryZone) gin.H {
+	return gin.H{
+		"id":           zone.ID,
+		"area":         zone.Area,
+		"district":     zone.District,
+		"sub_district": zone.SubDistrict,
+		"fee":          zone.Fee,
+		"free_above":   zone.FreeAbove,
+	}
+}
@@ -2,11 +2,15 @@ package admin_controllers
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/raihansyahrin/backend_laundry_app.git/config"
+	"github.com/raihansyahrin/backend_laundry_app.git/lifecycle"
 	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"github.com/raihansyahrin/backend_laundry_app.git/processing"
 	"github.com/raihansyahrin/backend_laundry_app.git/response"
+	"gorm.io/gorm"
 )
 
 func OrderComplete(c *gin.Context) {
@@ -25,7 +29,7 @@ func OrderComplete(c *gin.Context) {
 	}
 
 	var order models.Order
-	if err := config.DB.Preload("Service").Preload("Courier").Preload("Customer").Preload("Admin").Preload("Address").First(&order, body.OrderID).Error; err != nil {
+	if err := config.DB.Preload("Service").Preload("Courier").Preload("Customer").Preload("Admin").Preload("Address").Preload("Addons").Preload("Charges").Preload("Items.Addons").Preload("Payments").Preload("Refunds").Preload("PickupSlot").Preload("DeliverySlot").First(&order, body.OrderID).Error; err != nil {
 		c.JSON(http.StatusBadRequest, response.DefaultResponse{
 			Code:    http.StatusBadRequest,
 			Success: false,
@@ -58,9 +62,6 @@ func OrderComplete(c *gin.Context) {
 		return
 	}
 
-	// Ubah status pesanan menjadi 'done'
-	order.Status = "done"
-
 	// Type assertion to get uint value from adminID
 	adminIDUint, ok := adminID.(uint)
 	if !ok {
@@ -75,24 +76,47 @@ func OrderComplete(c *gin.Context) {
 
 	order.AdminID = &adminIDUint
 
-	if err := config.DB.Save(&order).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, response.DefaultResponse{
-			Code:    http.StatusInternalServerError,
+	// Ubah status pesanan menjadi 'done' dan selesaikan tahap pengerjaan terakhir
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lifecycle.Transition(tx, &order, models.OrderStatusDone, lifecycle.ActorFromContext(c), "admin_id"); err != nil {
+			return err
+		}
+		return processing.Finish(tx, order.ID, time.Now())
+	})
+	if err != nil {
+		code := lifecycle.ErrorStatus(err)
+		c.JSON(code, response.DefaultResponse{
+			Code:    code,
 			Success: false,
-			Message: "Failed to update order status",
+			Message: "Failed to update order status: " + err.Error(),
 			Data:    nil,
 		})
 		return
 	}
 
 	orderResponse := response.OrderResponse{
-		ID:         order.ID,
-		Status:     order.Status,
-		CreatedAt:  order.CreatedAt.String(),
-		UpdatedAt:  order.UpdatedAt.String(),
-		TotalPrice: order.TotalPrice,
-		Weight:     order.Weight,
-		Quantity:   order.Quantity,
+		ID:                  order.ID,
+		Status:              string(order.Status),
+		Area:                order.Area,
+		Addons:              response.NewOrderAddons(order.Addons),
+		Items:               response.NewOrderItems(order.Items),
+		PriceBreakdown:      response.NewPriceBreakdown(order.Charges),
+		PromoCode:           order.PromoCode,
+		Discount:            order.Discount,
+		AmountDue:           order.AmountDue(),
+		NetPaid:             order.NetPaid(),
+		DeliveryFee:         order.DeliveryFee,
+		DeliveryFeeBasis:    order.DeliveryFeeBasis,
+		ProcessingStage:     order.ProcessingStage,
+		PickupSlot:          response.NewTimeSlot(order.PickupSlot),
+		DeliverySlot:        response.NewTimeSlot(order.DeliverySlot),
+		EstimatedReadyAt:    order.EstimatedReadyAt,
+		EstimatedDeliveryAt: order.EstimatedDeliveryAt,
+		CreatedAt:           order.CreatedAt.String(),
+		UpdatedAt:           order.UpdatedAt.String(),
+		TotalPrice:          order.TotalPrice,
+		Weight:              order.Weight,
+		Quantity:            order.Quantity,
 		Customer: response.UserResponse{
 			ID:       order.Customer.ID,
 			Username: order.Customer.Username,
@@ -107,6 +131,7 @@ func OrderComplete(c *gin.Context) {
 			ID:    order.Service.ID,
 			Title: order.Service.Title,
 			Price: uint(order.Service.Price),
+			Unit:  order.ServiceUnit,
 		},
 		Courier: response.UserResponse{
 			ID:       order.Courier.ID,
@@ -126,6 +151,8 @@ func OrderComplete(c *gin.Context) {
 			SubDistrict:   order.Address.SubDistrict,
 			City:          order.Address.City,
 			Area:          order.Address.Area,
+			Latitude:      order.Address.Latitude,
+			Longitude:     order.Address.Longitude,
 		},
 	}
 
@@ -136,3 +163,69 @@ func OrderComplete(c *gin.Context) {
 		Data:    orderResponse,
 	})
 }
+
+// Status order yang cucian-nya belum selesai diproses
+var processingStatuses = []models.OrderStatus{
+	models.OrderStatusArrived,
+	models.OrderStatusWaitingForPayment,
+	models.OrderStatusInProgress,
+}
+
+// GetOverdueOrders lists open orders that are past their estimated ready
+// time while still being processed, or past their estimated delivery time.
+func GetOverdueOrders(c *gin.Context) {
+	now := time.Now()
+
+	var orders []models.Order
+	err := config.DB.Preload("Customer").Preload("Courier").
+		Where("status NOT IN ?", []models.OrderStatus{models.OrderStatusDelivered, models.OrderStatusCompleted, models.OrderStatusCancelled}).
+		Where("(status IN ? AND estimated_ready_at < ?) OR estimated_delivery_at < ?", processingStatuses, now, now).
+		Order("estimated_delivery_at").
+		Find(&orders).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.DefaultResponse{
+			Code:    http.StatusInternalServerError,
+			Success: false,
+			Message: "Failed to retrieve overdue orders",
+			Data:    nil,
+		})
+		return
+	}
+
+	overdue := []response.OverdueOrderResponse{}
+	for _, order := range orders {
+		entry := response.OverdueOrderResponse{
+			OrderID:             order.ID,
+			Status:              string(order.Status),
+			Breach:              "delivery",
+			EstimatedReadyAt:    order.EstimatedReadyAt,
+			EstimatedDeliveryAt: order.EstimatedDeliveryAt,
+			Customer: response.UserResponse{
+				ID:       order.Customer.ID,
+				Username: order.Customer.Username,
+				Email:    order.Customer.Email,
+			},
+			Courier: response.UserResponse{
+				ID:       order.Courier.ID,
+				Username: order.Courier.Username,
+				Email:    order.Courier.Email,
+			},
+		}
+		deadline := order.EstimatedDeliveryAt
+		if deadline == nil || deadline.After(now) {
+			entry.Breach = "ready"
+			deadline = order.EstimatedReadyAt
+		}
+		if deadline != nil {
+			entry.OverdueMinutes = int(now.Sub(*deadline).Minutes())
+		}
+		overdue = append(overdue, entry)
+	}
+
+	c.JSON(http.StatusOK, response.DefaultResponse{
+		Code:    http.StatusOK,
+		Success: true,
+		Message: "Overdue orders retrieved successfully",
+		Data:    overdue,
+	})
+}
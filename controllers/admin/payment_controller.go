@@ -0,0 +1,67 @@
+package admin_controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raihansyahrin/backend_laundry_app.git/config"
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"github.com/raihansyahrin/backend_laundry_app.git/payment"
+	"gorm.io/gorm"
+)
+
+// reportDay reads the report date from ?date=YYYY-MM-DD, yesterday by default.
+// It answers the request itself and returns false when the date is invalid.
+func reportDay(c *gin.Context) (time.Time, bool) {
+	day := time.Now().AddDate(0, 0, -1)
+	if date := c.Query("date"); date != "" {
+		parsed, err := time.ParseInLocation(payment.ReportDateLayout, date, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid date, expected YYYY-MM-DD"})
+			return day, false
+		}
+		day = parsed
+	}
+	return day, true
+}
+
+// GetReconciliationReport mengambil laporan rekonsiliasi yang sudah dibuat
+// untuk tanggal ?date=YYYY-MM-DD, default kemarin
+func GetReconciliationReport(c *gin.Context) {
+	day, ok := reportDay(c)
+	if !ok {
+		return
+	}
+
+	var report models.ReconciliationReport
+	err := config.DB.Where("date = ?", day.Format(payment.ReportDateLayout)).First(&report).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Reconciliation report has not been generated for this date"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve reconciliation report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": report, "code": 200, "success": true})
+}
+
+// GenerateReconciliationReport membuat ulang laporan rekonsiliasi untuk
+// tanggal ?date=YYYY-MM-DD, default kemarin
+func GenerateReconciliationReport(c *gin.Context) {
+	day, ok := reportDay(c)
+	if !ok {
+		return
+	}
+
+	report, err := payment.Reconcile(config.DB, day)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to build reconciliation report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": report, "code": 200, "success": true})
+}
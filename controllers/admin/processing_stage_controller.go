@@ -0,0 +1,193 @@
+package admin_controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raihansyahrin/backend_laundry_app.git/config"
+	"github.com/raihansyahrin/backend_laundry_app.git/lifecycle"
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"github.com/raihansyahrin/backend_laundry_app.git/processing"
+)
+
+type ProcessingStageController struct{}
+
+// processingStageInput is the body of create and update requests
+type processingStageInput struct {
+	Code     string `json:"code"`
+	Name     string `json:"name"`
+	Position int    `json:"position"`
+	Active   *bool  `json:"active"`
+}
+
+// GetProcessingStages mengambil semua tahap pengerjaan, termasuk yang tidak aktif
+func (pc *ProcessingStageController) GetProcessingStages(c *gin.Context) {
+	var stages []models.ProcessingStage
+	if err := config.DB.Order("position, id").Find(&stages).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve processing stages"})
+		return
+	}
+
+	data := []gin.H{}
+	for _, stage := range stages {
+		data = append(data, processingStageData(stage))
+	}
+	c.JSON(http.StatusOK, gin.H{"data": data, "code": 200, "success": true})
+}
+
+// CreateProcessingStage membuat tahap pengerjaan baru
+func (pc *ProcessingStageController) CreateProcessingStage(c *gin.Context) {
+	stage := models.ProcessingStage{Active: true}
+	if !bindProcessingStage(c, &stage) {
+		return
+	}
+
+	if err := config.DB.Create(&stage).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to create processing stage"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"success": true,
+		"message": "Processing stage created successfully",
+		"data":    processingStageData(stage),
+	})
+}
+
+// UpdateProcessingStage mengupdate tahap pengerjaan berdasarkan ID. Kode
+// tahap tidak bisa diubah karena dipakai di riwayat order.
+func (pc *ProcessingStageController) UpdateProcessingStage(c *gin.Context) {
+	var stage models.ProcessingStage
+	if err := config.DB.First(&stage, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Processing stage not found"})
+		return
+	}
+	if !bindProcessingStage(c, &stage) {
+		return
+	}
+
+	if err := config.DB.Save(&stage).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to update processing stage"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"success": true,
+		"message": "Processing stage updated successfully",
+		"data":    processingStageData(stage),
+	})
+}
+
+// DeleteProcessingStage menghapus tahap pengerjaan berdasarkan ID
+func (pc *ProcessingStageController) DeleteProcessingStage(c *gin.Context) {
+	if err := config.DB.Delete(&models.ProcessingStage{}, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to delete processing stage"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Processing stage deleted successfully"})
+}
+
+// bindProcessingStage validates the request body and copies it onto stage.
+// It answers the request itself and returns false when the body is invalid.
+func bindProcessingStage(c *gin.Context, stage *models.ProcessingStage) bool {
+	var input processingStageInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input format"})
+		return false
+	}
+
+	if stage.ID == 0 {
+		stage.Code = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(input.Code)), " ", "_")
+		if stage.Code == "" || len(stage.Code) > 50 {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Code is required and at most 50 characters"})
+			return false
+		}
+		var existing int64
+		if err := config.DB.Model(&models.ProcessingStage{}).Where("code = ?", stage.Code).Count(&existing).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to check processing stage"})
+			return false
+		}
+		if existing > 0 {
+			c.JSON(http.StatusConflict, gin.H{"message": "A processing stage with this code already exists"})
+			return false
+		}
+	}
+
+	stage.Name = strings.TrimSpace(input.Name)
+	stage.Position = input.Position
+	if input.Active != nil {
+		stage.Active = *input.Active
+	}
+	if stage.Name == "" {
+		stage.Name = stage.Code
+	}
+	if stage.Position < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Position cannot be negative"})
+		return false
+	}
+	return true
+}
+
+func processingStageData(stage models.ProcessingStage) gin.H {
+	return gin.H{
+		"id":       stage.ID,
+		"code":     stage.Code,
+		"name":     stage.Name,
+		"position": stage.Position,
+		"active":   stage.Active,
+	}
+}
+
+// AdvanceOrderStage moves an order in progress to its next processing stage,
+// or to the stage given in the body
+func AdvanceOrderStage(c *gin.Context) {
+	var body struct {
+		Stage string `json:"stage" form:"stage"`
+	}
+	if err := c.ShouldBind(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input format"})
+		return
+	}
+
+	var order models.Order
+	if err := config.DB.First(&order, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Order not found"})
+		return
+	}
+
+	event, err := processing.Advance(config.DB, &order, strings.TrimSpace(body.Stage), lifecycle.ActorFromContext(c).UserID)
+	if err != nil {
+		if processing.IsStageError(err) {
+			c.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to update processing stage"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"success": true,
+		"message": "Processing stage updated successfully",
+		"data": gin.H{
+			"order_id":   order.ID,
+			"stage":      event.Stage,
+			"started_at": event.StartedAt,
+		},
+	})
+}
+
+// GetStageSummary counts the orders in progress per processing stage
+func GetStageSummary(c *gin.Context) {
+	summary, err := processing.Summary(config.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve processing stage summary"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": summary, "code": 200, "success": true})
+}
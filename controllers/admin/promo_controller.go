@@ -0,0 +1,156 @@
+package admin_controllers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raihansyahrin/backend_laundry_app.git/config"
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PromoController struct{}
+
+// GetPromos mengambil semua kode promo
+func (pc *PromoController) GetPromos(c *gin.Context) {
+	var promos []models.Promo
+	if err := config.DB.Order("id desc").Find(&promos).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve promos"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": promos, "code": 200, "success": true})
+}
+
+// CreatePromo membuat kode promo baru
+func (pc *PromoController) CreatePromo(c *gin.Context) {
+	promo := models.Promo{Active: true}
+	if err := c.ShouldBind(&promo); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input format"})
+		return
+	}
+	promo.ID = 0
+
+	if message := validatePromo(promo); message != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": message})
+		return
+	}
+
+	if err := config.DB.Create(&promo).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to create promo"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"success": true,
+		"message": "Promo created successfully",
+		"data":    promo,
+	})
+}
+
+// errPromoRedeemed is returned when the discount of a redeemed promo is edited
+var errPromoRedeemed = errors.New("promo has been redeemed")
+
+// discountTermsChanged reports whether the update changes how much discount
+// orders that already redeemed the promo get
+func discountTermsChanged(current, updated models.Promo) bool {
+	return !strings.EqualFold(strings.TrimSpace(current.Code), strings.TrimSpace(updated.Code)) ||
+		current.Type != updated.Type || current.Value != updated.Value ||
+		current.MaxDiscount != updated.MaxDiscount || current.MinOrderValue != updated.MinOrderValue ||
+		current.Categories != updated.Categories
+}
+
+// UpdatePromo mengupdate kode promo berdasarkan ID. Setelah promo dipakai,
+// besaran diskonnya tidak bisa diubah lagi.
+func (pc *PromoController) UpdatePromo(c *gin.Context) {
+	id := c.Param("id")
+
+	var promo models.Promo
+	if err := config.DB.First(&promo, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Promo not found"})
+		return
+	}
+
+	promoID := promo.ID
+	if err := c.ShouldBindJSON(&promo); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input format"})
+		return
+	}
+	promo.ID = promoID
+
+	if message := validatePromo(promo); message != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": message})
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// Baris promo dikunci seperti di pricing.ApplyPromo, jadi pengecekan
+		// redemption tidak bisa didahului oleh customer yang memakai promo
+		var current models.Promo
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, promo.ID).Error; err != nil {
+			return err
+		}
+		if discountTermsChanged(current, promo) {
+			var redeemed int64
+			if err := tx.Model(&models.PromoRedemption{}).Where("promo_id = ?", promo.ID).Count(&redeemed).Error; err != nil {
+				return err
+			}
+			if redeemed > 0 {
+				return errPromoRedeemed
+			}
+		}
+		// Select("*") agar field bernilai nol seperti active=false ikut tersimpan
+		return tx.Select("*").Omit("created_at").Updates(&promo).Error
+	})
+	if errors.Is(err, errPromoRedeemed) {
+		c.JSON(http.StatusConflict, gin.H{"message": "Promo has been redeemed, only its validity, limits and status can be changed"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to update promo"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Promo updated successfully", "promo": promo})
+}
+
+// DeletePromo menghapus kode promo berdasarkan ID
+func (pc *PromoController) DeletePromo(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := config.DB.Delete(&models.Promo{}, id).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to delete promo"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Promo deleted successfully"})
+}
+
+func validatePromo(promo models.Promo) string {
+	if promo.Code == "" {
+		return "Promo code is required"
+	}
+	switch promo.Type {
+	case models.PromoPercent:
+		if promo.Value <= 0 || promo.Value > 100 {
+			return "Percent promo value must be between 0 and 100"
+		}
+	case models.PromoFixed:
+		if promo.Value <= 0 {
+			return "Fixed promo value must be greater than zero"
+		}
+	default:
+		return "Promo type must be percent or fixed"
+	}
+	if promo.ValidFrom != nil && promo.ValidUntil != nil && promo.ValidUntil.Before(*promo.ValidFrom) {
+		return "Promo validity window ends before it starts"
+	}
+	if promo.UsageLimit < 0 || promo.PerUserLimit < 0 || promo.MinOrderValue < 0 || promo.MaxDiscount < 0 {
+		return "Promo limits cannot be negative"
+	}
+	return ""
+}
@@ -0,0 +1,69 @@
+package admin_controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raihansyahrin/backend_laundry_app.git/config"
+	"github.com/raihansyahrin/backend_laundry_app.git/lifecycle"
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"github.com/raihansyahrin/backend_laundry_app.git/payment"
+)
+
+// CreateRefund mengembalikan sebagian atau seluruh pembayaran order. Tanpa
+// amount, semua yang masih bisa dikembalikan akan direfund.
+func CreateRefund(c *gin.Context) {
+	var body struct {
+		Amount float64 `json:"amount" form:"amount"`
+		Reason string  `json:"reason" form:"reason"`
+	}
+	if err := c.ShouldBind(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input format"})
+		return
+	}
+	body.Reason = strings.TrimSpace(body.Reason)
+	if body.Reason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Reason is required"})
+		return
+	}
+	if body.Amount < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Amount must be positive"})
+		return
+	}
+
+	var order models.Order
+	if err := config.DB.First(&order, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Order not found"})
+		return
+	}
+
+	actor := lifecycle.ActorFromContext(c)
+	refunds, err := payment.RefundOrder(c.Request.Context(), config.DB, order.ID, body.Amount, body.Reason, actor.UserID)
+	if err != nil {
+		if payment.IsRefundError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadGateway, gin.H{"message": "Failed to refund order", "error": err.Error(), "data": refunds})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"success": true,
+		"message": "Order refunded successfully",
+		"data":    refunds,
+	})
+}
+
+// GetRefunds mengambil semua refund sebuah order
+func GetRefunds(c *gin.Context) {
+	var refunds []models.Refund
+	if err := config.DB.Where("order_id = ?", c.Param("id")).Order("id").Find(&refunds).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve refunds"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": refunds, "code": 200, "success": true})
+}
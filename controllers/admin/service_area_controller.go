@@ -0,0 +1,165 @@
+package admin_controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raihansyahrin/backend_laundry_app.git/config"
+	"github.com/raihansyahrin/backend_laundry_app.git/geo"
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+)
+
+type ServiceAreaController struct{}
+
+// serviceAreaInput is the body of create and update requests
+type serviceAreaInput struct {
+	Outlet    string      `json:"outlet"`
+	Kind      string      `json:"kind"`
+	CenterLat float64     `json:"center_lat"`
+	CenterLng float64     `json:"center_lng"`
+	RadiusKm  float64     `json:"radius_km"`
+	Polygon   []geo.Point `json:"polygon"`
+	Active    *bool       `json:"active"`
+}
+
+// GetServiceAreas mengambil semua area layanan
+func (sc *ServiceAreaController) GetServiceAreas(c *gin.Context) {
+	var areas []models.ServiceArea
+	if err := config.DB.Order("id").Find(&areas).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve service areas"})
+		return
+	}
+
+	data := []gin.H{}
+	for _, area := range areas {
+		data = append(data, serviceAreaData(area))
+	}
+	c.JSON(http.StatusOK, gin.H{"data": data, "code": 200, "success": true})
+}
+
+// CreateServiceArea membuat area layanan baru untuk sebuah outlet
+func (sc *ServiceAreaController) CreateServiceArea(c *gin.Context) {
+	area := models.ServiceArea{Active: true}
+	if !bindServiceArea(c, &area) {
+		return
+	}
+
+	if err := config.DB.Create(&area).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to create service area"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"success": true,
+		"message": "Service area created successfully",
+		"data":    serviceAreaData(area),
+	})
+}
+
+// UpdateServiceArea mengupdate area layanan berdasarkan ID
+func (sc *ServiceAreaController) UpdateServiceArea(c *gin.Context) {
+	var area models.ServiceArea
+	if err := config.DB.First(&area, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Service area not found"})
+		return
+	}
+	if !bindServiceArea(c, &area) {
+		return
+	}
+
+	if err := config.DB.Save(&area).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to update service area"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"success": true,
+		"message": "Service area updated successfully",
+		"data":    serviceAreaData(area),
+	})
+}
+
+// DeleteServiceArea menghapus area layanan berdasarkan ID
+func (sc *ServiceAreaController) DeleteServiceArea(c *gin.Context) {
+	if err := config.DB.Delete(&models.ServiceArea{}, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to delete service area"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Service area deleted successfully"})
+}
+
+// bindServiceArea validates the request body and copies it onto area. It
+// answers the request itself and returns false when the body is invalid.
+func bindServiceArea(c *gin.Context, area *models.ServiceArea) bool {
+	var input serviceAreaInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input format"})
+		return false
+	}
+
+	area.Outlet = strings.TrimSpace(input.Outlet)
+	area.Kind = input.Kind
+	if input.Active != nil {
+		area.Active = *input.Active
+	}
+	if area.Outlet == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Outlet is required"})
+		return false
+	}
+
+	switch area.Kind {
+	case models.ServiceAreaRadius:
+		center := geo.Point{Lat: input.CenterLat, Lng: input.CenterLng}
+		if !center.Valid() || input.RadiusKm <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Radius areas need a valid center and a radius greater than zero"})
+			return false
+		}
+		area.CenterLat, area.CenterLng, area.RadiusKm = center.Lat, center.Lng, input.RadiusKm
+		area.Polygon = ""
+	case models.ServiceAreaPolygon:
+		if len(input.Polygon) < 3 {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Polygon areas need at least three points"})
+			return false
+		}
+		for _, point := range input.Polygon {
+			if !point.Valid() {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "Polygon contains invalid coordinates"})
+				return false
+			}
+		}
+		polygon, err := json.Marshal(input.Polygon)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid polygon"})
+			return false
+		}
+		area.Polygon = string(polygon)
+		area.CenterLat, area.CenterLng, area.RadiusKm = 0, 0, 0
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Kind must be radius or polygon"})
+		return false
+	}
+	return true
+}
+
+func serviceAreaData(area models.ServiceArea) gin.H {
+	data := gin.H{
+		"id":     area.ID,
+		"outlet": area.Outlet,
+		"kind":   area.Kind,
+		"active": area.Active,
+	}
+	if area.Kind == models.ServiceAreaPolygon {
+		points, _ := area.Points()
+		data["polygon"] = points
+	} else {
+		data["center_lat"] = area.CenterLat
+		data["center_lng"] = area.CenterLng
+		data["radius_km"] = area.RadiusKm
+	}
+	return data
+}
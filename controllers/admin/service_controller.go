@@ -7,6 +7,8 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/raihansyahrin/backend_laundry_app.git/config"
 	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"github.com/raihansyahrin/backend_laundry_app.git/response"
+	"gorm.io/gorm"
 )
 
 type ServiceController struct{}
@@ -14,7 +16,7 @@ type ServiceController struct{}
 // GetServices mengambil semua layanan
 func (sc *ServiceController) GetServices(c *gin.Context) {
 	var services []models.Service
-	if err := config.DB.Find(&services).Error; err != nil {
+	if err := config.DB.Preload("Addons").Find(&services).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve services"})
 		return
 	}
@@ -22,13 +24,7 @@ func (sc *ServiceController) GetServices(c *gin.Context) {
 	// Mengubah format data response
 	var serviceResponse []gin.H
 	for _, service := range services {
-		serviceResponse = append(serviceResponse, gin.H{
-			"id":       service.ID,
-			"title":    service.Title,
-			"time":     service.Time,
-			"price":    service.Price,
-			"category": service.Category,
-		})
+		serviceResponse = append(serviceResponse, serviceData(service))
 	}
 
 	c.JSON(http.StatusOK, gin.H{"data": serviceResponse})
@@ -42,20 +38,14 @@ func (sc *ServiceController) GetServiceByCategory(c *gin.Context) {
 	categoryEndpoint := strings.ReplaceAll(strings.ToLower(category), " ", "_")
 
 	var services []models.Service
-	if err := config.DB.Where("category = ?", category).Find(&services).Error; err != nil {
+	if err := config.DB.Preload("Addons").Where("category = ?", category).Find(&services).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve services by category"})
 		return
 	}
 
 	var serviceResponse []gin.H
 	for _, service := range services {
-		serviceResponse = append(serviceResponse, gin.H{
-			"id":       service.ID,
-			"title":    service.Title,
-			"time":     service.Time,
-			"price":    service.Price,
-			"category": service.Category,
-		})
+		serviceResponse = append(serviceResponse, serviceData(service))
 	}
 
 	c.JSON(http.StatusOK, gin.H{"data": serviceResponse, "category": categoryEndpoint, "code": 200, "success": true})
@@ -66,18 +56,12 @@ func (sc *ServiceController) GetServiceByID(c *gin.Context) {
 	id := c.Param("id")
 
 	var service models.Service
-	if err := config.DB.First(&service, id).Error; err != nil {
+	if err := config.DB.Preload("Addons").First(&service, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"message": "Service not found"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"id":       service.ID,
-		"title":    service.Title,
-		"time":     service.Time,
-		"price":    service.Price,
-		"category": service.Category,
-	})
+	c.JSON(http.StatusOK, serviceData(service))
 }
 
 // CreateService membuat layanan baru
@@ -87,8 +71,21 @@ func (sc *ServiceController) CreateService(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input format"})
 		return
 	}
+	// Add-on dikelola lewat endpoint tersendiri
+	service.Addons = nil
 
-	if err := config.DB.Create(&service).Error; err != nil {
+	if message := validatePricingRules(service); message != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": message})
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&service).Error; err != nil {
+			return err
+		}
+		return recordServiceVersion(tx, c, service, 0)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to create service"})
 		return
 	}
@@ -97,13 +94,7 @@ func (sc *ServiceController) CreateService(c *gin.Context) {
 		"code":    200,
 		"success": true,
 		"message": "Service created successfully",
-		"data": gin.H{
-			"id":       service.ID,
-			"title":    service.Title,
-			"time":     service.Time,
-			"price":    service.Price,
-			"category": service.Category,
-		},
+		"data":    serviceData(service),
 	})
 }
 
@@ -123,12 +114,38 @@ func (sc *ServiceController) UpdateService(c *gin.Context) {
 		return
 	}
 
+	previous := service
 	service.Title = updatedService.Title
 	service.Time = updatedService.Time
 	service.Price = updatedService.Price
 	service.Category = updatedService.Category // tambahkan update untuk category
+	// Unit yang tidak dikirim tetap memakai unit sebelumnya
+	if updatedService.Unit != "" {
+		service.Unit = updatedService.Unit
+	}
+	service.MinimumCharge = updatedService.MinimumCharge
+	service.WeightStep = updatedService.WeightStep
+	service.WeightRounding = updatedService.WeightRounding
+
+	if message := validatePricingRules(service); message != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": message})
+		return
+	}
 
-	if err := config.DB.Save(&service).Error; err != nil {
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&service).Error; err != nil {
+			return err
+		}
+		// Catat versi baru hanya jika data yang mempengaruhi harga berubah
+		if previous.Title == service.Title && previous.Price == service.Price &&
+			previous.Category == service.Category && previous.EffectiveUnit() == service.EffectiveUnit() &&
+			previous.MinimumCharge == service.MinimumCharge && previous.WeightStep == service.WeightStep &&
+			previous.WeightRounding == service.WeightRounding {
+			return nil
+		}
+		return recordServiceVersion(tx, c, service, previous.Price)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to update service"})
 		return
 	}
@@ -147,3 +164,139 @@ func (sc *ServiceController) DeleteService(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Service deleted successfully"})
 }
+
+// GetServicePriceHistory mengambil riwayat versi harga sebuah layanan
+func (sc *ServiceController) GetServicePriceHistory(c *gin.Context) {
+	id := c.Param("id")
+
+	var history []models.ServicePriceHistory
+	if err := config.DB.Preload("ChangedBy").Where("service_id = ?", id).Order("created_at, id").Find(&history).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve service price history"})
+		return
+	}
+
+	var historyResponse []gin.H
+	for _, version := range history {
+		historyResponse = append(historyResponse, gin.H{
+			"id":             version.ID,
+			"title":          version.Title,
+			"category":       version.Category,
+			"unit":           version.Unit,
+			"price":          version.Price,
+			"previous_price": version.PreviousPrice,
+			"changed_at":     version.CreatedAt.Format("2006-01-02 15:04:05"),
+			"changed_by": response.UserResponse{
+				ID:       version.ChangedBy.ID,
+				Username: version.ChangedBy.Username,
+				Email:    version.ChangedBy.Email,
+			},
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": historyResponse, "code": 200, "success": true})
+}
+
+// recordServiceVersion menyimpan versi layanan beserta admin yang mengubahnya
+func recordServiceVersion(tx *gorm.DB, c *gin.Context, service models.Service, previousPrice float64) error {
+	adminID, _ := c.Get("user_id")
+	changedByID, _ := adminID.(uint)
+	return tx.Create(&models.ServicePriceHistory{
+		ServiceID:     service.ID,
+		Title:         service.Title,
+		Category:      service.Category,
+		Unit:          service.Unit,
+		Price:         service.Price,
+		PreviousPrice: previousPrice,
+		ChangedByID:   changedByID,
+	}).Error
+}
+
+// CreateServiceAddon menambahkan add-on (express, parfum, setrika, hanger) ke layanan
+func (sc *ServiceController) CreateServiceAddon(c *gin.Context) {
+	id := c.Param("id")
+
+	var service models.Service
+	if err := config.DB.First(&service, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Service not found"})
+		return
+	}
+
+	var addon models.ServiceAddon
+	if err := c.ShouldBind(&addon); err != nil || addon.Code == "" || addon.Price < 0 || addon.Time < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input format"})
+		return
+	}
+	addon.ID = 0
+	addon.ServiceID = service.ID
+	if addon.Name == "" {
+		addon.Name = addon.Code
+	}
+
+	if err := config.DB.Create(&addon).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to create add-on"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"success": true,
+		"message": "Add-on created successfully",
+		"data":    addonData(addon),
+	})
+}
+
+// DeleteServiceAddon menghapus add-on dari layanan
+func (sc *ServiceController) DeleteServiceAddon(c *gin.Context) {
+	if err := config.DB.Where("service_id = ?", c.Param("id")).Delete(&models.ServiceAddon{}, c.Param("addon_id")).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to delete add-on"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Add-on deleted successfully"})
+}
+
+// validatePricingRules memastikan unit dan aturan pembulatan layanan dikenal
+func validatePricingRules(service models.Service) string {
+	if service.Unit != "" && !models.IsValidUnit(service.Unit) {
+		return "Unit must be one of kg, piece, pair or m2"
+	}
+	switch service.WeightRounding {
+	case "", models.RoundingNone, models.RoundingUp, models.RoundingNearest:
+	default:
+		return "Weight rounding must be none, up or nearest"
+	}
+	if service.Price < 0 || service.MinimumCharge < 0 || service.WeightStep < 0 {
+		return "Price, minimum charge and weight step cannot be negative"
+	}
+	return ""
+}
+
+func serviceData(service models.Service) gin.H {
+	addons := []gin.H{}
+	for _, addon := range service.Addons {
+		addons = append(addons, addonData(addon))
+	}
+	return gin.H{
+		"id":              service.ID,
+		"title":           service.Title,
+		"time":            service.Time,
+		"price":           service.Price,
+		"category":        service.Category,
+		"unit":            service.EffectiveUnit(),
+		"minimum_charge":  service.MinimumCharge,
+		"weight_step":     service.WeightStep,
+		"weight_rounding": service.WeightRounding,
+		"addons":          addons,
+	}
+}
+
+func addonData(addon models.ServiceAddon) gin.H {
+	return gin.H{
+		"id":       addon.ID,
+		"code":     addon.Code,
+		"name":     addon.Name,
+		"price":    addon.Price,
+		"per_unit": addon.PerUnit,
+		"time":     addon.Time,
+	}
+}
@@ -0,0 +1,139 @@
+package admin_controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raihansyahrin/backend_laundry_app.git/config"
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"github.com/raihansyahrin/backend_laundry_app.git/response"
+	"github.com/raihansyahrin/backend_laundry_app.git/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// StaffInviteTTL is how long an invite token can be redeemed
+const StaffInviteTTL = 72 * time.Hour
+
+// CreateStaff creates a courier or admin account with a password set by the admin
+func CreateStaff(c *gin.Context) {
+	var body struct {
+		Username string `json:"username" form:"username"`
+		Email    string `json:"email" form:"email"`
+		Password string `json:"password" form:"password"`
+		Role     string `json:"role" form:"role"`
+	}
+
+	if err := c.ShouldBind(&body); err != nil || body.Email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input format"})
+		return
+	}
+
+	if !models.IsStaffRole(body.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Role must be courier or admin"})
+		return
+	}
+
+	var existing int64
+	config.DB.Model(&models.User{}).Where("email = ?", body.Email).Count(&existing)
+	if existing > 0 {
+		c.JSON(http.StatusConflict, gin.H{"message": "Email is already registered"})
+		return
+	}
+
+	if len(body.Password) < 8 {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Password must be at least 8 characters long"})
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(body.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Error hashing password"})
+		return
+	}
+
+	user := models.User{
+		Username: body.Username,
+		Email:    body.Email,
+		Password: string(hash),
+		Role:     body.Role,
+	}
+
+	if err := config.DB.Create(&user).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to create user"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Staff account created successfully",
+		"code":    http.StatusCreated,
+		"data": response.UserResponse{
+			ID:       user.ID,
+			Username: user.Username,
+			Email:    user.Email,
+			Role:     &user.Role,
+		},
+	})
+}
+
+// InviteStaff creates an invite for a courier or admin. The returned token is
+// shown only once and is redeemed at /api/auth/invites/redeem.
+func InviteStaff(c *gin.Context) {
+	var body struct {
+		Username string `json:"username" form:"username"`
+		Email    string `json:"email" form:"email"`
+		Role     string `json:"role" form:"role"`
+	}
+
+	if err := c.ShouldBind(&body); err != nil || body.Email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input format"})
+		return
+	}
+
+	if !models.IsStaffRole(body.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Role must be courier or admin"})
+		return
+	}
+
+	var existing int64
+	config.DB.Model(&models.User{}).Where("email = ?", body.Email).Count(&existing)
+	if existing > 0 {
+		c.JSON(http.StatusConflict, gin.H{"message": "Email is already registered"})
+		return
+	}
+
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to generate invite token"})
+		return
+	}
+
+	adminID, _ := c.Get("user_id")
+	invite := models.StaffInvite{
+		Email:       body.Email,
+		Username:    body.Username,
+		Role:        body.Role,
+		TokenHash:   utils.HashToken(token),
+		InvitedByID: adminID.(uint),
+		ExpiresAt:   time.Now().Add(StaffInviteTTL),
+	}
+
+	if err := config.DB.Create(&invite).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to create invite"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Invite created successfully",
+		"code":    http.StatusCreated,
+		"data": gin.H{
+			"id":         invite.ID,
+			"email":      invite.Email,
+			"role":       invite.Role,
+			"token":      token,
+			"expires_at": invite.ExpiresAt.Format("2006-01-02 15:04:05"),
+		},
+	})
+}
@@ -0,0 +1,174 @@
+package admin_controllers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raihansyahrin/backend_laundry_app.git/config"
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"github.com/raihansyahrin/backend_laundry_app.git/response"
+	"github.com/raihansyahrin/backend_laundry_app.git/scheduling"
+)
+
+type TimeSlotController struct{}
+
+// GetTimeSlots mengambil slot yang masih bisa dipesan, bisa difilter dengan
+// ?kind=pickup|delivery, ?date=YYYY-MM-DD dan ?area=
+func (tc *TimeSlotController) GetTimeSlots(c *gin.Context) {
+	query := config.DB.Where("date >= ? AND booked < capacity", time.Now().Format(models.SlotDateLayout))
+	if kind := c.Query("kind"); kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	if date := c.Query("date"); date != "" {
+		query = query.Where("date = ?", date)
+	}
+
+	var slots []models.TimeSlot
+	if err := query.Order("date, start_time").Find(&slots).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve time slots"})
+		return
+	}
+
+	area := c.Query("area")
+	now := time.Now()
+	data := []response.TimeSlotResponse{}
+	for i := range slots {
+		if area != "" && !scheduling.ServesArea(&slots[i], area) {
+			continue
+		}
+		if start, err := slots[i].Start(); err != nil || !start.After(now) {
+			continue
+		}
+		data = append(data, *response.NewTimeSlot(slots[i]))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": data, "code": 200, "success": true})
+}
+
+// CreateTimeSlot membuat slot penjemputan atau pengantaran baru
+func (tc *TimeSlotController) CreateTimeSlot(c *gin.Context) {
+	var slot models.TimeSlot
+	if err := c.ShouldBind(&slot); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input format"})
+		return
+	}
+	slot.ID = 0
+	slot.Booked = 0
+	slot.Area = strings.TrimSpace(slot.Area)
+
+	if message := validateTimeSlot(slot); message != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": message})
+		return
+	}
+
+	if err := config.DB.Create(&slot).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to create time slot"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"success": true,
+		"message": "Time slot created successfully",
+		"data":    slot,
+	})
+}
+
+// UpdateTimeSlot mengubah slot, kapasitas tidak boleh di bawah jumlah order
+// yang sudah memesan slot tersebut
+func (tc *TimeSlotController) UpdateTimeSlot(c *gin.Context) {
+	var slot models.TimeSlot
+	if err := config.DB.First(&slot, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Time slot not found"})
+		return
+	}
+
+	id, booked := slot.ID, slot.Booked
+	if err := c.ShouldBind(&slot); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input format"})
+		return
+	}
+	slot.ID, slot.Booked = id, booked
+	slot.Area = strings.TrimSpace(slot.Area)
+
+	if message := validateTimeSlot(slot); message != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": message})
+		return
+	}
+	if slot.Capacity < slot.Booked {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Capacity cannot be lower than the number of booked orders"})
+		return
+	}
+
+	// Booked hanya diubah saat order memesan slot, jadi tidak ikut ditulis di
+	// sini dan kapasitas dicek lagi terhadap nilai booked terbaru
+	result := config.DB.Model(&slot).
+		Where("booked <= ?", slot.Capacity).
+		Select("kind", "date", "start_time", "end_time", "area", "capacity").
+		Updates(&slot)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to update time slot"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"message": "Capacity cannot be lower than the number of booked orders"})
+		return
+	}
+	if err := config.DB.First(&slot, slot.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve time slot"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"success": true,
+		"message": "Time slot updated successfully",
+		"data":    slot,
+	})
+}
+
+// DeleteTimeSlot menghapus slot yang belum dipesan
+func (tc *TimeSlotController) DeleteTimeSlot(c *gin.Context) {
+	var slot models.TimeSlot
+	if err := config.DB.First(&slot, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Time slot not found"})
+		return
+	}
+	if slot.Booked > 0 {
+		c.JSON(http.StatusConflict, gin.H{"message": "Time slot already has bookings"})
+		return
+	}
+
+	if err := config.DB.Delete(&slot).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to delete time slot"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Time slot deleted successfully"})
+}
+
+func validateTimeSlot(slot models.TimeSlot) string {
+	if slot.Kind != models.SlotKindPickup && slot.Kind != models.SlotKindDelivery {
+		return "Kind must be pickup or delivery"
+	}
+	if _, err := time.Parse(models.SlotDateLayout, slot.Date); err != nil {
+		return "Date must be formatted as YYYY-MM-DD"
+	}
+	start, err := time.Parse(models.SlotTimeLayout, slot.StartTime)
+	if err != nil {
+		return "Start time must be formatted as HH:MM"
+	}
+	end, err := time.Parse(models.SlotTimeLayout, slot.EndTime)
+	if err != nil {
+		return "End time must be formatted as HH:MM"
+	}
+	if !end.After(start) {
+		return "End time must be after start time"
+	}
+	if slot.Capacity <= 0 {
+		return "Capacity must be greater than zero"
+	}
+	return ""
+}
@@ -1,22 +1,26 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/raihansyahrin/backend_laundry_app.git/config"
 	"github.com/raihansyahrin/backend_laundry_app.git/models"
 	"github.com/raihansyahrin/backend_laundry_app.git/utils"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
+var errInviteRedeemed = errors.New("invite already redeemed")
+
 func Register(c *gin.Context) {
 	var body struct {
 		Username        string `json:"username" form:"username"`
 		Email           string `json:"email" form:"email"`
 		Password        string `json:"password" form:"password"`
 		ConfirmPassword string `json:"confirm_password" form:"confirm_password"`
-		Role            string `json:"role" form:"role"`
 	}
 
 	// Binding request body into the struct
@@ -44,12 +48,12 @@ func Register(c *gin.Context) {
 		return
 	}
 
-	// Create user object
+	// Registrasi mandiri selalu membuat customer, akun kurir dan admin dibuat oleh admin
 	user := models.User{
 		Username: body.Username,
 		Email:    body.Email,
 		Password: string(hash),
-		Role:     body.Role,
+		Role:     models.RoleCustomer,
 	}
 
 	// Save user to database
@@ -87,7 +91,21 @@ func Login(c *gin.Context) {
 		return
 	}
 
-	token, err := utils.GenerateJWT(user.ID, user.Email, user.Role) // Tambahkan role ke token
+	session := models.Session{
+		UserID:    user.ID,
+		UserAgent: c.Request.UserAgent(),
+	}
+	refreshToken, err := rotateRefreshToken(&session)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to generate token"})
+		return
+	}
+	if err := config.DB.Create(&session).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to create session"})
+		return
+	}
+
+	token, err := utils.GenerateJWT(user.ID, user.Email, user.Role, session.ID) // Tambahkan role ke token
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to generate token"})
 		return
@@ -98,8 +116,200 @@ func Login(c *gin.Context) {
 		"message": "Login successful!",
 		"code":    http.StatusOK,
 		"data": gin.H{
-			"token": token,
-			"role":  user.Role,
+			"token":         token,
+			"refresh_token": refreshToken,
+			"expires_in":    int(utils.AccessTokenTTL.Seconds()),
+			"role":          user.Role,
 		},
 	})
 }
+
+// rotateRefreshToken gives the session a new refresh token and extends its
+// expiry. The previous hash is kept to detect reuse of a rotated token.
+func rotateRefreshToken(session *models.Session) (string, error) {
+	refreshToken, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	session.PreviousTokenHash = session.RefreshTokenHash
+	session.RefreshTokenHash = utils.HashToken(refreshToken)
+	session.ExpiresAt = time.Now().Add(utils.RefreshTokenTTL)
+	return refreshToken, nil
+}
+
+// RefreshToken exchanges a refresh token for a new access token and a new
+// refresh token. Presenting an already rotated token revokes the session.
+func RefreshToken(c *gin.Context) {
+	var body struct {
+		RefreshToken string `json:"refresh_token" form:"refresh_token"`
+	}
+
+	if err := c.ShouldBind(&body); err != nil || body.RefreshToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input format"})
+		return
+	}
+
+	hash := utils.HashToken(body.RefreshToken)
+
+	var session models.Session
+	if err := config.DB.Where("refresh_token_hash = ?", hash).First(&session).Error; err != nil {
+		// Token lama dipakai ulang, kemungkinan dicuri: cabut sesinya
+		if config.DB.Where("previous_token_hash = ?", hash).First(&session).Error == nil {
+			config.DB.Model(&session).Where("revoked_at IS NULL").Update("revoked_at", time.Now())
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "Invalid refresh token"})
+		return
+	}
+
+	if session.RevokedAt != nil || session.ExpiresAt.Before(time.Now()) {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "Session has expired or been revoked"})
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, session.UserID).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "User no longer exists"})
+		return
+	}
+
+	refreshToken, err := rotateRefreshToken(&session)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to generate token"})
+		return
+	}
+
+	// Hanya satu permintaan refresh yang boleh memakai token yang sama
+	result := config.DB.Model(&session).Where("refresh_token_hash = ? AND revoked_at IS NULL", hash).Updates(map[string]interface{}{
+		"refresh_token_hash":  session.RefreshTokenHash,
+		"previous_token_hash": session.PreviousTokenHash,
+		"expires_at":          session.ExpiresAt,
+	})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to refresh session"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "Invalid refresh token"})
+		return
+	}
+
+	token, err := utils.GenerateJWT(user.ID, user.Email, user.Role, session.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to generate token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Token refreshed",
+		"code":    http.StatusOK,
+		"data": gin.H{
+			"token":         token,
+			"refresh_token": refreshToken,
+			"expires_in":    int(utils.AccessTokenTTL.Seconds()),
+			"role":          user.Role,
+		},
+	})
+}
+
+// Logout revokes the session of the current access token
+func Logout(c *gin.Context) {
+	sessionID, _ := c.Get("session_id")
+
+	if err := config.DB.Model(&models.Session{}).Where("id = ? AND revoked_at IS NULL", sessionID).Update("revoked_at", time.Now()).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to logout"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Logged out successfully"})
+}
+
+// LogoutAll revokes every session of the current user, logging out all devices
+func LogoutAll(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	if err := config.DB.Model(&models.Session{}).Where("user_id = ? AND revoked_at IS NULL", userID).Update("revoked_at", time.Now()).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to logout from all devices"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Logged out from all devices"})
+}
+
+// RedeemInvite creates the courier or admin account of a staff invite with
+// the password chosen by the invited staff member
+func RedeemInvite(c *gin.Context) {
+	var body struct {
+		Token           string `json:"token" form:"token"`
+		Username        string `json:"username" form:"username"`
+		Password        string `json:"password" form:"password"`
+		ConfirmPassword string `json:"confirm_password" form:"confirm_password"`
+	}
+
+	if err := c.ShouldBind(&body); err != nil || body.Token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input format"})
+		return
+	}
+
+	if len(body.Password) < 8 {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Password must be at least 8 characters long"})
+		return
+	}
+
+	if body.Password != body.ConfirmPassword {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Passwords do not match"})
+		return
+	}
+
+	var invite models.StaffInvite
+	if err := config.DB.Where("token_hash = ? AND redeemed_at IS NULL AND expires_at > ?", utils.HashToken(body.Token), time.Now()).First(&invite).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invite is invalid or has expired"})
+		return
+	}
+
+	if !models.IsStaffRole(invite.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invite is invalid or has expired"})
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(body.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Error hashing password"})
+		return
+	}
+
+	user := models.User{
+		Username: invite.Username,
+		Email:    invite.Email,
+		Password: string(hash),
+		Role:     invite.Role,
+	}
+	if body.Username != "" {
+		user.Username = body.Username
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		// Tandai undangan terpakai lebih dulu agar token tidak bisa dipakai dua kali
+		result := tx.Model(&invite).Where("redeemed_at IS NULL").Update("redeemed_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errInviteRedeemed
+		}
+		return tx.Create(&user).Error
+	})
+	if err == errInviteRedeemed {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invite is invalid or has expired"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to create user"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Account created successfully",
+	})
+}
@@ -0,0 +1,86 @@
+package courier_controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raihansyahrin/backend_laundry_app.git/config"
+	"github.com/raihansyahrin/backend_laundry_app.git/lifecycle"
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"github.com/raihansyahrin/backend_laundry_app.git/response"
+)
+
+// ConfirmDelivery marks an order delivered once the assigned courier submits
+// the code the customer sees in their order detail
+func ConfirmDelivery(c *gin.Context) {
+	var body struct {
+		Code string `json:"code" form:"code"`
+	}
+	if err := c.ShouldBind(&body); err != nil || body.Code == "" {
+		c.JSON(http.StatusBadRequest, response.DefaultResponse{
+			Code:    http.StatusBadRequest,
+			Success: false,
+			Message: "Delivery code is required",
+			Data:    nil,
+		})
+		return
+	}
+
+	var order models.Order
+	if err := config.DB.First(&order, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, response.DefaultResponse{
+			Code:    http.StatusNotFound,
+			Success: false,
+			Message: "Order not found",
+			Data:    nil,
+		})
+		return
+	}
+
+	err := lifecycle.ConfirmDelivery(config.DB, &order, body.Code, lifecycle.ActorFromContext(c))
+	if errors.Is(err, lifecycle.ErrInvalidDeliveryCode) {
+		c.JSON(http.StatusUnprocessableEntity, response.DefaultResponse{
+			Code:    http.StatusUnprocessableEntity,
+			Success: false,
+			Message: err.Error(),
+			Data:    gin.H{"attempts_left": lifecycle.MaxDeliveryCodeAttempts - order.DeliveryCodeAttempts},
+		})
+		return
+	}
+	if errors.Is(err, lifecycle.ErrNoDeliveryCode) {
+		c.JSON(http.StatusConflict, response.DefaultResponse{
+			Code:    http.StatusConflict,
+			Success: false,
+			Message: err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+	if errors.Is(err, lifecycle.ErrDeliveryCodeLocked) {
+		c.JSON(http.StatusTooManyRequests, response.DefaultResponse{
+			Code:    http.StatusTooManyRequests,
+			Success: false,
+			Message: err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+	if err != nil {
+		code := lifecycle.ErrorStatus(err)
+		c.JSON(code, response.DefaultResponse{
+			Code:    code,
+			Success: false,
+			Message: "Failed to confirm delivery: " + err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.DefaultResponse{
+		Code:    http.StatusOK,
+		Success: true,
+		Message: "Order delivered",
+		Data:    gin.H{"order_id": order.ID, "status": order.Status},
+	})
+}
@@ -0,0 +1,134 @@
+package courier_controllers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raihansyahrin/backend_laundry_app.git/config"
+	"github.com/raihansyahrin/backend_laundry_app.git/dispatch"
+	"github.com/raihansyahrin/backend_laundry_app.git/lifecycle"
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"github.com/raihansyahrin/backend_laundry_app.git/response"
+	"gorm.io/gorm"
+)
+
+// GetJobBoard lists the orders waiting for a courier in the logged-in
+// courier's areas, oldest first
+func GetJobBoard(c *gin.Context) {
+	actor := lifecycle.ActorFromContext(c)
+
+	orders, err := dispatch.OpenJobs(config.DB, actor.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.DefaultResponse{
+			Code:    http.StatusInternalServerError,
+			Success: false,
+			Message: "Failed to retrieve open orders",
+			Data:    nil,
+		})
+		return
+	}
+
+	now := time.Now()
+	jobs := []response.JobResponse{}
+	for _, order := range orders {
+		job := response.JobResponse{
+			OrderID:        order.ID,
+			CreatedAt:      order.CreatedAt,
+			WaitingMinutes: int(now.Sub(order.CreatedAt).Minutes()),
+			Services:       []string{},
+			PickupSlot:     response.NewTimeSlot(order.PickupSlot),
+			Address:        response.NewAddress(order.Address),
+		}
+		for _, item := range order.Items {
+			job.Services = append(job.Services, item.ServiceTitle)
+		}
+		if len(order.Items) == 0 && order.ServiceTitle != "" {
+			job.Services = append(job.Services, order.ServiceTitle)
+		}
+		jobs = append(jobs, job)
+	}
+
+	c.JSON(http.StatusOK, response.DefaultResponse{
+		Code:    http.StatusOK,
+		Success: true,
+		Message: "Open orders retrieved successfully",
+		Data:    jobs,
+	})
+}
+
+// GetCourierAreas retrieves the service areas of a courier
+func GetCourierAreas(c *gin.Context) {
+	var courier models.User
+	if err := config.DB.Where("role = ? AND id = ?", models.RoleCourier, c.Param("id")).First(&courier).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Courier not found"})
+		return
+	}
+
+	areas, err := dispatch.CourierAreas(config.DB, courier.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve courier areas"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Successfully retrieved courier areas",
+		"code":    http.StatusOK,
+		"data":    areas,
+	})
+}
+
+// SetCourierAreas replaces the service areas of a courier
+func SetCourierAreas(c *gin.Context) {
+	var body struct {
+		Areas []models.CourierArea `json:"areas"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input format"})
+		return
+	}
+
+	var courier models.User
+	if err := config.DB.Where("role = ? AND id = ?", models.RoleCourier, c.Param("id")).First(&courier).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Courier not found"})
+		return
+	}
+
+	areas := []models.CourierArea{}
+	for _, area := range body.Areas {
+		area.Area = strings.TrimSpace(area.Area)
+		if area.Area == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Area is required"})
+			return
+		}
+		areas = append(areas, models.CourierArea{
+			CourierID:   courier.ID,
+			Area:        area.Area,
+			District:    strings.TrimSpace(area.District),
+			SubDistrict: strings.TrimSpace(area.SubDistrict),
+		})
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("courier_id = ?", courier.ID).Delete(&models.CourierArea{}).Error; err != nil {
+			return err
+		}
+		if len(areas) == 0 {
+			return nil
+		}
+		return tx.Create(&areas).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to update courier areas"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Courier areas updated successfully",
+		"code":    http.StatusOK,
+		"data":    areas,
+	})
+}
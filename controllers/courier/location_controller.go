@@ -0,0 +1,82 @@
+package courier_controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raihansyahrin/backend_laundry_app.git/config"
+	"github.com/raihansyahrin/backend_laundry_app.git/geo"
+	"github.com/raihansyahrin/backend_laundry_app.git/lifecycle"
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"github.com/raihansyahrin/backend_laundry_app.git/response"
+	"github.com/raihansyahrin/backend_laundry_app.git/tracking"
+)
+
+// UpdateLocation stores the current GPS position of the logged-in courier.
+// Positions are only accepted while the courier is travelling for an order.
+func UpdateLocation(c *gin.Context) {
+	var body struct {
+		Latitude  *float64 `json:"latitude" form:"latitude"`
+		Longitude *float64 `json:"longitude" form:"longitude"`
+		Accuracy  float64  `json:"accuracy" form:"accuracy"`
+	}
+	if err := c.ShouldBind(&body); err != nil || body.Latitude == nil || body.Longitude == nil {
+		c.JSON(http.StatusBadRequest, response.DefaultResponse{
+			Code:    http.StatusBadRequest,
+			Success: false,
+			Message: "Latitude and longitude are required",
+			Data:    nil,
+		})
+		return
+	}
+	point := geo.Point{Lat: *body.Latitude, Lng: *body.Longitude}
+	if !point.Valid() {
+		c.JSON(http.StatusBadRequest, response.DefaultResponse{
+			Code:    http.StatusBadRequest,
+			Success: false,
+			Message: "Invalid coordinates",
+			Data:    nil,
+		})
+		return
+	}
+
+	actor := lifecycle.ActorFromContext(c)
+	var active int64
+	if err := config.DB.Model(&models.Order{}).Where("courier_id = ? AND status IN ?", actor.UserID, tracking.TrackedStatuses).Count(&active).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, response.DefaultResponse{
+			Code:    http.StatusInternalServerError,
+			Success: false,
+			Message: "Failed to check active orders",
+			Data:    nil,
+		})
+		return
+	}
+	if active == 0 {
+		c.JSON(http.StatusConflict, response.DefaultResponse{
+			Code:    http.StatusConflict,
+			Success: false,
+			Message: "Courier has no order on the way",
+			Data:    nil,
+		})
+		return
+	}
+
+	location, err := tracking.Record(config.DB, actor.UserID, point, body.Accuracy, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.DefaultResponse{
+			Code:    http.StatusInternalServerError,
+			Success: false,
+			Message: "Failed to store location",
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.DefaultResponse{
+		Code:    http.StatusOK,
+		Success: true,
+		Message: "Location updated successfully",
+		Data:    location,
+	})
+}
@@ -1,12 +1,21 @@
 package courier_controllers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/raihansyahrin/backend_laundry_app.git/config"
+	"github.com/raihansyahrin/backend_laundry_app.git/dispatch"
+	"github.com/raihansyahrin/backend_laundry_app.git/lifecycle"
 	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"github.com/raihansyahrin/backend_laundry_app.git/payment"
+	"github.com/raihansyahrin/backend_laundry_app.git/pricing"
 	"github.com/raihansyahrin/backend_laundry_app.git/response"
+	"github.com/raihansyahrin/backend_laundry_app.git/scheduling"
+	"gorm.io/gorm"
 )
 
 func AcceptOrder(c *gin.Context) {
@@ -27,7 +36,7 @@ func AcceptOrder(c *gin.Context) {
 	}
 
 	var order models.Order
-	if err := config.DB.Preload("Service").Preload("Courier").Preload("Customer").Preload("Admin").Preload("Address").First(&order, orderID).Error; err != nil {
+	if err := config.DB.Preload("Service").Preload("Courier").Preload("Customer").Preload("Admin").Preload("Address").Preload("Addons").Preload("Charges").Preload("Items.Addons").Preload("Payments").Preload("Refunds").Preload("PickupSlot").Preload("DeliverySlot").First(&order, orderID).Error; err != nil {
 		c.JSON(http.StatusBadRequest, response.DefaultResponse{
 			Code:    http.StatusBadRequest,
 			Success: false,
@@ -37,17 +46,6 @@ func AcceptOrder(c *gin.Context) {
 		return
 	}
 
-	// Cek apakah order sudah diterima sebelumnya
-	if order.CourierID != nil {
-		c.JSON(http.StatusConflict, response.DefaultResponse{
-			Code:    http.StatusConflict,
-			Success: false,
-			Message: "Order has already been accepted by another courier",
-			Data:    nil,
-		})
-		return
-	}
-
 	courierID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, response.DefaultResponse{
@@ -83,29 +81,71 @@ func AcceptOrder(c *gin.Context) {
 		return
 	}
 
-	// Tetapkan courier_id dan ubah status order
-	order.CourierID = &courierIDUint // Gunakan courierIDUint yang telah dikonversi
-	order.Status = "Kurir On The Way"
-	order.AdminID = nil // Hapus pengaturan AdminID karena tidak ada admin yang menerima order
-
-	if err := config.DB.Save(&order).Error; err != nil {
+	// Kurir hanya boleh menerima order di area layanannya
+	areas, err := dispatch.CourierAreas(config.DB, courierIDUint)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.DefaultResponse{
 			Code:    http.StatusInternalServerError,
 			Success: false,
-			Message: "Failed to accept order",
+			Message: "Failed to retrieve courier areas",
+			Data:    nil,
+		})
+		return
+	}
+	if !dispatch.Serves(areas, order.Address) {
+		c.JSON(http.StatusForbidden, response.DefaultResponse{
+			Code:    http.StatusForbidden,
+			Success: false,
+			Message: dispatch.ErrOutsideArea.Error(),
+			Data:    nil,
+		})
+		return
+	}
+
+	// Tetapkan courier_id dan ubah status order, hanya satu kurir yang bisa menang
+	if err := dispatch.Claim(config.DB, &order, courierIDUint, lifecycle.ActorFromContext(c)); err != nil {
+		if errors.Is(err, dispatch.ErrAlreadyClaimed) {
+			c.JSON(http.StatusConflict, response.DefaultResponse{
+				Code:    http.StatusConflict,
+				Success: false,
+				Message: "Order has already been accepted by another courier",
+				Data:    nil,
+			})
+			return
+		}
+		code := lifecycle.ErrorStatus(err)
+		c.JSON(code, response.DefaultResponse{
+			Code:    code,
+			Success: false,
+			Message: "Failed to accept order: " + err.Error(),
 			Data:    nil,
 		})
 		return
 	}
 
 	orderResponse := response.OrderResponse{
-		ID:         order.ID,
-		Status:     order.Status,
-		CreatedAt:  order.CreatedAt.String(),
-		UpdatedAt:  order.UpdatedAt.String(),
-		TotalPrice: order.TotalPrice,
-		Weight:     order.Weight,
-		Quantity:   order.Quantity,
+		ID:                  order.ID,
+		Status:              string(order.Status),
+		Area:                order.Area,
+		Addons:              response.NewOrderAddons(order.Addons),
+		Items:               response.NewOrderItems(order.Items),
+		PriceBreakdown:      response.NewPriceBreakdown(order.Charges),
+		PromoCode:           order.PromoCode,
+		Discount:            order.Discount,
+		AmountDue:           order.AmountDue(),
+		NetPaid:             order.NetPaid(),
+		DeliveryFee:         order.DeliveryFee,
+		DeliveryFeeBasis:    order.DeliveryFeeBasis,
+		ProcessingStage:     order.ProcessingStage,
+		PickupSlot:          response.NewTimeSlot(order.PickupSlot),
+		DeliverySlot:        response.NewTimeSlot(order.DeliverySlot),
+		EstimatedReadyAt:    order.EstimatedReadyAt,
+		EstimatedDeliveryAt: order.EstimatedDeliveryAt,
+		CreatedAt:           order.CreatedAt.String(),
+		UpdatedAt:           order.UpdatedAt.String(),
+		TotalPrice:          order.TotalPrice,
+		Weight:              order.Weight,
+		Quantity:            order.Quantity,
 		Customer: response.UserResponse{
 			ID:       order.Customer.ID,
 			Username: order.Customer.Username,
@@ -120,6 +160,7 @@ func AcceptOrder(c *gin.Context) {
 			ID:    order.Service.ID,
 			Title: order.Service.Title,
 			Price: uint(order.Service.Price),
+			Unit:  order.ServiceUnit,
 		},
 		Courier: response.UserResponse{
 			ID:       order.Courier.ID,
@@ -139,6 +180,8 @@ func AcceptOrder(c *gin.Context) {
 			SubDistrict:   order.Address.SubDistrict,
 			City:          order.Address.City,
 			Area:          order.Address.Area,
+			Latitude:      order.Address.Latitude,
+			Longitude:     order.Address.Longitude,
 		},
 	}
 
@@ -150,11 +193,21 @@ func AcceptOrder(c *gin.Context) {
 	})
 }
 
+// itemMeasurement is what the courier measured for one order line at pickup
+type itemMeasurement struct {
+	ItemID   uint    `json:"item_id"`
+	Weight   float64 `json:"weight,omitempty"`
+	Quantity int     `json:"quantity,omitempty"`
+	Area     float64 `json:"area,omitempty"`
+}
+
 func CourierArrived(c *gin.Context) {
 	var body struct {
-		OrderID  uint    `json:"order_id" form:"order_id"`
-		Weight   float64 `json:"weight,omitempty" form:"weight"`
-		Quantity int     `json:"quantity,omitempty" form:"quantity"`
+		OrderID  uint              `json:"order_id" form:"order_id"`
+		Weight   float64           `json:"weight,omitempty" form:"weight"`
+		Quantity int               `json:"quantity,omitempty" form:"quantity"`
+		Area     float64           `json:"area,omitempty" form:"area"`
+		Items    []itemMeasurement `json:"items"`
 	}
 
 	if err := c.ShouldBind(&body); err != nil {
@@ -163,51 +216,132 @@ func CourierArrived(c *gin.Context) {
 	}
 
 	var order models.Order
-	if err := config.DB.First(&order, body.OrderID).Error; err != nil {
+	if err := config.DB.Preload("Addons").Preload("Items.Addons").Preload("Payments").Preload("Refunds").Preload("PickupSlot").Preload("DeliverySlot").First(&order, body.OrderID).Error; err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid order ID"})
 		return
 	}
 
-	// Fetch the service to get its price
-	var service models.Service
-	if err := config.DB.First(&service, order.ServiceID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve service details"})
-		return
-	}
-
-	var totalPrice float64
-	if service.Category == "Laundry Satuan" {
-		order.Quantity = body.Quantity
-		totalPrice = float64(service.Price) * float64(order.Quantity)
-		order.Weight = 0 // Reset weight if it was set
-	} else {
-		order.Weight = body.Weight
-		totalPrice = float64(service.Price) * order.Weight
-		order.Quantity = 0 // Reset quantity if it was set
+	// Order lama hanya punya satu layanan tanpa order item
+	legacy := len(order.Items) == 0
+	if legacy {
+		if order.ServiceTitle == "" {
+			var service models.Service
+			if err := config.DB.First(&service, order.ServiceID).Error; err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve service details"})
+				return
+			}
+			order.SnapshotService(service)
+		}
+		order.Items = []models.OrderItem{order.LegacyItem()}
+	}
+
+	// Ukuran tanpa item_id berlaku untuk order yang hanya punya satu layanan
+	if len(body.Items) == 0 {
+		if len(order.Items) != 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Please provide a measurement for every order item"})
+			return
+		}
+		body.Items = []itemMeasurement{{ItemID: order.Items[0].ID, Weight: body.Weight, Quantity: body.Quantity, Area: body.Area}}
+	}
+
+	measurements := map[uint]itemMeasurement{}
+	for _, measurement := range body.Items {
+		measurements[measurement.ItemID] = measurement
+	}
+
+	order.Weight, order.Quantity, order.Area = 0, 0, 0
+	for i := range order.Items {
+		item := &order.Items[i]
+		measurement, ok := measurements[item.ID]
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"message": fmt.Sprintf("Measurement missing for item %d (%s)", item.ID, item.ServiceTitle)})
+			return
+		}
+
+		// Simpan hanya ukuran yang sesuai dengan unit layanan
+		item.Weight, item.Quantity, item.Area = 0, 0, 0
+		switch item.ServiceUnit {
+		case models.UnitKg:
+			item.Weight = measurement.Weight
+		case models.UnitSquareMeter:
+			item.Area = measurement.Area
+		default:
+			item.Quantity = measurement.Quantity
+		}
+		if item.Weight <= 0 && item.Quantity <= 0 && item.Area <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"message": fmt.Sprintf("Measurement for item %d (%s) must be greater than zero", item.ID, item.ServiceTitle)})
+			return
+		}
+
+		order.Weight += item.Weight
+		order.Quantity += item.Quantity
+		order.Area += item.Area
 	}
 
-	order.TotalPrice = totalPrice
-	order.Status = "arrived - proses pembayaran"
-
 	// Update the order in the database
-	if err := config.DB.Save(&order).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to update order status and weight/quantity", "error": err.Error()})
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		for i := range order.Items {
+			item := &order.Items[i]
+			if legacy {
+				if err := tx.Omit("Addons").Create(item).Error; err != nil {
+					return err
+				}
+				if err := tx.Model(&models.OrderAddon{}).Where("order_id = ?", order.ID).Update("order_item_id", item.ID).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			if err := tx.Model(item).Select("weight", "quantity", "area").Updates(item).Error; err != nil {
+				return err
+			}
+		}
+		if err := pricing.Apply(tx, &order); err != nil {
+			return err
+		}
+		// Estimasi selesai dihitung sejak cucian dijemput
+		if err := scheduling.Estimate(tx, &order, time.Now(), scheduling.WorkingHoursFromEnv()); err != nil {
+			return err
+		}
+		columns := append([]string{"weight", "quantity", "area", "estimated_ready_at", "estimated_delivery_at",
+			"service_title", "service_category", "service_unit", "service_price", "service_minimum_charge",
+			"service_weight_step", "service_weight_rounding"}, pricing.Columns...)
+		return lifecycle.Transition(tx, &order, models.OrderStatusArrived, lifecycle.ActorFromContext(c), columns...)
+	})
+	if err != nil {
+		c.JSON(lifecycle.ErrorStatus(err), gin.H{"message": "Failed to update order status and weight/quantity", "error": err.Error()})
 		return
 	}
 
 	// Preload associated data before responding
-	if err := config.DB.Preload("Address").Preload("Customer").Preload("Admin").Preload("Service").Preload("Courier").First(&order, order.ID).Error; err != nil {
+	if err := config.DB.Preload("Address").Preload("Addons").Preload("Charges").Preload("Items.Addons").Preload("Payments").Preload("Refunds").Preload("PickupSlot").Preload("DeliverySlot").Preload("Customer").Preload("Admin").Preload("Service").Preload("Courier").First(&order, order.ID).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve updated order with associated data", "error": err.Error()})
 		return
 	}
 
 	// Prepare response
 	orderResponse := response.OrderResponse{
-		ID:         order.ID,
-		Status:     order.Status,
-		CreatedAt:  order.CreatedAt.String(),
-		UpdatedAt:  order.UpdatedAt.String(),
-		TotalPrice: totalPrice,
+		ID:                  order.ID,
+		Status:              string(order.Status),
+		Area:                order.Area,
+		Addons:              response.NewOrderAddons(order.Addons),
+		Items:               response.NewOrderItems(order.Items),
+		PriceBreakdown:      response.NewPriceBreakdown(order.Charges),
+		PromoCode:           order.PromoCode,
+		Discount:            order.Discount,
+		AmountDue:           order.AmountDue(),
+		NetPaid:             order.NetPaid(),
+		DeliveryFee:         order.DeliveryFee,
+		DeliveryFeeBasis:    order.DeliveryFeeBasis,
+		ProcessingStage:     order.ProcessingStage,
+		PickupSlot:          response.NewTimeSlot(order.PickupSlot),
+		DeliverySlot:        response.NewTimeSlot(order.DeliverySlot),
+		EstimatedReadyAt:    order.EstimatedReadyAt,
+		EstimatedDeliveryAt: order.EstimatedDeliveryAt,
+		CreatedAt:           order.CreatedAt.String(),
+		UpdatedAt:           order.UpdatedAt.String(),
+		TotalPrice:          order.TotalPrice,
+		Weight:              order.Weight,
+		Quantity:            order.Quantity,
 		Customer: response.UserResponse{
 			ID:       order.Customer.ID,
 			Username: order.Customer.Username,
@@ -222,6 +356,7 @@ func CourierArrived(c *gin.Context) {
 			ID:    order.Service.ID,
 			Title: order.Service.Title,
 			Price: uint(order.Service.Price),
+			Unit:  order.ServiceUnit,
 		},
 		Courier: response.UserResponse{
 			ID:       order.Courier.ID,
@@ -241,17 +376,11 @@ func CourierArrived(c *gin.Context) {
 			SubDistrict:   order.Address.SubDistrict,
 			City:          order.Address.City,
 			Area:          order.Address.Area,
+			Latitude:      order.Address.Latitude,
+			Longitude:     order.Address.Longitude,
 		},
 	}
 
-	if service.Title == "Laundry Satuan" {
-		orderResponse.Quantity = order.Quantity
-		orderResponse.Weight = 0
-	} else {
-		orderResponse.Weight = order.Weight
-		orderResponse.Quantity = 0
-	}
-
 	// Return response
 	c.JSON(http.StatusOK, response.DefaultResponse{
 		Success: true,
@@ -278,7 +407,7 @@ func AcceptCashPayment(c *gin.Context) {
 
 	var order models.Order
 	// Pastikan untuk preload kolom yang diperlukan
-	if err := config.DB.Preload("Service").Preload("Courier").Preload("Customer").Preload("Address").First(&order, body.OrderID).Error; err != nil {
+	if err := config.DB.Preload("Service").Preload("Courier").Preload("Customer").Preload("Address").Preload("Addons").Preload("Charges").Preload("Items.Addons").Preload("Payments").Preload("Refunds").Preload("PickupSlot").Preload("DeliverySlot").First(&order, body.OrderID).Error; err != nil {
 		c.JSON(http.StatusBadRequest, response.DefaultResponse{
 			Code:    http.StatusBadRequest,
 			Success: false,
@@ -312,17 +441,6 @@ func AcceptCashPayment(c *gin.Context) {
 		return
 	}
 
-	// Validasi apakah pesanan sudah tiba di lokasi
-	if order.Status != "arrived - proses pembayaran" {
-		c.JSON(http.StatusConflict, response.DefaultResponse{
-			Code:    http.StatusConflict,
-			Success: false,
-			Message: "Order is not in 'arrived - proses pembayaran' status",
-			Data:    nil,
-		})
-		return
-	}
-
 	// Konversi courierID ke uint
 	courierIDUint, ok := courierID.(uint)
 	if !ok {
@@ -346,43 +464,54 @@ func AcceptCashPayment(c *gin.Context) {
 		return
 	}
 
-	// Hitung total harga
-	var totalPrice float64
-	if order.Service.Category == "Laundry Satuan" {
-		totalPrice = float64(order.Service.Price) * float64(order.Quantity)
-		order.TotalPrice = totalPrice
-		order.Weight = 0 // Reset weight if it was set
-	} else {
-		totalPrice = float64(order.Service.Price) * float64(order.Weight)
-		order.TotalPrice = totalPrice
-		order.Quantity = 0 // Reset quantity if it was set
-	}
-
-	// Ubah status pesanan menjadi 'in progress'
-	order.Status = "in progress"
+	// Total harga sudah dihitung oleh pricing saat kurir tiba
 
-	// Save order without overwriting total_price and admin_id
-	if err := config.DB.Model(&order).Updates(map[string]interface{}{
-		"status":      order.Status,
-		"total_price": order.TotalPrice,
-	}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, response.DefaultResponse{
-			Code:    http.StatusInternalServerError,
+	// Ubah status pesanan menjadi 'in progress' dan catat pembayaran tunai
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lifecycle.Transition(tx, &order, models.OrderStatusInProgress, lifecycle.ActorFromContext(c)); err != nil {
+			return err
+		}
+		record := models.NewCashPayment(&order, payment.NewReference(order.ID), &courierIDUint)
+		if err := tx.Create(&record).Error; err != nil {
+			return err
+		}
+		order.Payments = append(order.Payments, record)
+		return nil
+	})
+	if err != nil {
+		code := lifecycle.ErrorStatus(err)
+		c.JSON(code, response.DefaultResponse{
+			Code:    code,
 			Success: false,
-			Message: "Failed to update order status",
+			Message: "Failed to update order status: " + err.Error(),
 			Data:    nil,
 		})
 		return
 	}
 
 	orderResponse := response.OrderResponse{
-		ID:         order.ID,
-		Status:     order.Status,
-		CreatedAt:  order.CreatedAt.String(),
-		UpdatedAt:  order.UpdatedAt.String(),
-		TotalPrice: order.TotalPrice,
-		Weight:     order.Weight,
-		Quantity:   order.Quantity,
+		ID:                  order.ID,
+		Status:              string(order.Status),
+		Area:                order.Area,
+		Addons:              response.NewOrderAddons(order.Addons),
+		Items:               response.NewOrderItems(order.Items),
+		PriceBreakdown:      response.NewPriceBreakdown(order.Charges),
+		PromoCode:           order.PromoCode,
+		Discount:            order.Discount,
+		AmountDue:           order.AmountDue(),
+		NetPaid:             order.NetPaid(),
+		DeliveryFee:         order.DeliveryFee,
+		DeliveryFeeBasis:    order.DeliveryFeeBasis,
+		ProcessingStage:     order.ProcessingStage,
+		PickupSlot:          response.NewTimeSlot(order.PickupSlot),
+		DeliverySlot:        response.NewTimeSlot(order.DeliverySlot),
+		EstimatedReadyAt:    order.EstimatedReadyAt,
+		EstimatedDeliveryAt: order.EstimatedDeliveryAt,
+		CreatedAt:           order.CreatedAt.String(),
+		UpdatedAt:           order.UpdatedAt.String(),
+		TotalPrice:          order.TotalPrice,
+		Weight:              order.Weight,
+		Quantity:            order.Quantity,
 		Customer: response.UserResponse{
 			ID:       order.Customer.ID,
 			Username: order.Customer.Username,
@@ -397,6 +526,7 @@ func AcceptCashPayment(c *gin.Context) {
 			ID:    order.Service.ID,
 			Title: order.Service.Title,
 			Price: uint(order.Service.Price),
+			Unit:  order.ServiceUnit,
 		},
 		Address: response.AddressResponse{
 			ID:            order.Address.ID,
@@ -411,6 +541,8 @@ func AcceptCashPayment(c *gin.Context) {
 			SubDistrict:   order.Address.SubDistrict,
 			City:          order.Address.City,
 			Area:          order.Address.Area,
+			Latitude:      order.Address.Latitude,
+			Longitude:     order.Address.Longitude,
 		},
 	}
 
@@ -438,7 +570,7 @@ func OrderDelivery(c *gin.Context) {
 	}
 
 	var order models.Order
-	if err := config.DB.Preload("Service").Preload("Courier").Preload("Customer").Preload("Admin").Preload("Address").First(&order, body.OrderID).Error; err != nil {
+	if err := config.DB.Preload("Service").Preload("Courier").Preload("Customer").Preload("Admin").Preload("Address").Preload("Addons").Preload("Charges").Preload("Items.Addons").Preload("Payments").Preload("Refunds").Preload("PickupSlot").Preload("DeliverySlot").First(&order, body.OrderID).Error; err != nil {
 		c.JSON(http.StatusBadRequest, response.DefaultResponse{
 			Code:    http.StatusBadRequest,
 			Success: false,
@@ -471,17 +603,6 @@ func OrderDelivery(c *gin.Context) {
 		return
 	}
 
-	// Validasi apakah pesanan sudah selesai di proses
-	if order.Status != "done" {
-		c.JSON(http.StatusConflict, response.DefaultResponse{
-			Code:    http.StatusConflict,
-			Success: false,
-			Message: "Order is not marked as done",
-			Data:    nil,
-		})
-		return
-	}
-
 	// Konversi courierID ke uint
 	courierIDUint, ok := courierID.(uint)
 	if !ok {
@@ -495,27 +616,55 @@ func OrderDelivery(c *gin.Context) {
 	}
 
 	// Ubah status pesanan menjadi 'delivering' dan set courier yang akan mengantar
-	order.Status = "delivering"
 	order.CourierID = &courierIDUint
 
-	if err := config.DB.Save(&order).Error; err != nil {
+	// Kode pengantaran ditampilkan ke customer dan diminta kurir saat serah terima
+	code, err := lifecycle.NewDeliveryCode()
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.DefaultResponse{
 			Code:    http.StatusInternalServerError,
 			Success: false,
-			Message: "Failed to update order status",
+			Message: "Failed to generate delivery code",
+			Data:    nil,
+		})
+		return
+	}
+	order.DeliveryCode, order.DeliveryCodeAttempts = code, 0
+
+	if err := lifecycle.Transition(config.DB, &order, models.OrderStatusDelivering, lifecycle.ActorFromContext(c), "courier_id", "delivery_code", "delivery_code_attempts"); err != nil {
+		code := lifecycle.ErrorStatus(err)
+		c.JSON(code, response.DefaultResponse{
+			Code:    code,
+			Success: false,
+			Message: "Failed to update order status: " + err.Error(),
 			Data:    nil,
 		})
 		return
 	}
 
 	orderResponse := response.OrderResponse{
-		ID:         order.ID,
-		Status:     order.Status,
-		CreatedAt:  order.CreatedAt.String(),
-		UpdatedAt:  order.UpdatedAt.String(),
-		TotalPrice: order.TotalPrice,
-		Weight:     order.Weight,
-		Quantity:   order.Quantity,
+		ID:                  order.ID,
+		Status:              string(order.Status),
+		Area:                order.Area,
+		Addons:              response.NewOrderAddons(order.Addons),
+		Items:               response.NewOrderItems(order.Items),
+		PriceBreakdown:      response.NewPriceBreakdown(order.Charges),
+		PromoCode:           order.PromoCode,
+		Discount:            order.Discount,
+		AmountDue:           order.AmountDue(),
+		NetPaid:             order.NetPaid(),
+		DeliveryFee:         order.DeliveryFee,
+		DeliveryFeeBasis:    order.DeliveryFeeBasis,
+		ProcessingStage:     order.ProcessingStage,
+		PickupSlot:          response.NewTimeSlot(order.PickupSlot),
+		DeliverySlot:        response.NewTimeSlot(order.DeliverySlot),
+		EstimatedReadyAt:    order.EstimatedReadyAt,
+		EstimatedDeliveryAt: order.EstimatedDeliveryAt,
+		CreatedAt:           order.CreatedAt.String(),
+		UpdatedAt:           order.UpdatedAt.String(),
+		TotalPrice:          order.TotalPrice,
+		Weight:              order.Weight,
+		Quantity:            order.Quantity,
 		Customer: response.UserResponse{
 			ID:       order.Customer.ID,
 			Username: order.Customer.Username,
@@ -530,6 +679,7 @@ func OrderDelivery(c *gin.Context) {
 			ID:    order.Service.ID,
 			Title: order.Service.Title,
 			Price: uint(order.Service.Price),
+			Unit:  order.ServiceUnit,
 		},
 		Courier: response.UserResponse{
 			ID:       order.Courier.ID,
@@ -549,6 +699,8 @@ func OrderDelivery(c *gin.Context) {
 			SubDistrict:   order.Address.SubDistrict,
 			City:          order.Address.City,
 			Area:          order.Address.Area,
+			Latitude:      order.Address.Latitude,
+			Longitude:     order.Address.Longitude,
 		},
 	}
 
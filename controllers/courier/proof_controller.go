@@ -0,0 +1,268 @@
+package courier_controllers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raihansyahrin/backend_laundry_app.git/config"
+	"github.com/raihansyahrin/backend_laundry_app.git/lifecycle"
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"github.com/raihansyahrin/backend_laundry_app.git/response"
+	"github.com/raihansyahrin/backend_laundry_app.git/storage"
+	"gorm.io/gorm"
+)
+
+// errStoreProof is returned when a valid file could not be written to storage
+var errStoreProof = errors.New("failed to store proof file")
+
+// maxProofPhotos is the number of photos accepted in one upload
+const maxProofPhotos = 5
+
+// proofStatuses are the statuses in which each proof stage can be uploaded:
+// at pickup while the courier is at the customer, at delivery while the
+// laundry is on its way back or right after it was handed over
+var proofStatuses = map[string][]models.OrderStatus{
+	models.ProofStagePickup:   {models.OrderStatusCourierOnTheWay, models.OrderStatusArrived},
+	models.ProofStageDelivery: {models.OrderStatusDelivering, models.OrderStatusDelivered},
+}
+
+// proofExtensions are the accepted image types and the extension they are
+// stored with
+var proofExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+// maxProofSize reads UPLOAD_MAX_SIZE_MB, the size limit of each file
+func maxProofSize() int64 {
+	const fallback = 5
+	value := os.Getenv("UPLOAD_MAX_SIZE_MB")
+	if value == "" {
+		return fallback << 20
+	}
+	size, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || size <= 0 {
+		log.Printf("storage: invalid UPLOAD_MAX_SIZE_MB %q, using %d", value, fallback)
+		return fallback << 20
+	}
+	return size << 20
+}
+
+// UploadProof stores photos, and optionally the recipient's name and
+// signature, taken by the assigned courier at pickup or at delivery. The
+// multipart form carries "photos" (one or more images), "signature" (one
+// image) and "recipient_name".
+func UploadProof(c *gin.Context) {
+	stage := c.Param("stage")
+	allowed, ok := proofStatuses[stage]
+	if !ok {
+		c.JSON(http.StatusBadRequest, response.DefaultResponse{
+			Code:    http.StatusBadRequest,
+			Success: false,
+			Message: "Stage must be pickup or delivery",
+			Data:    nil,
+		})
+		return
+	}
+
+	files := storage.Default()
+	if files == nil {
+		c.JSON(http.StatusServiceUnavailable, response.DefaultResponse{
+			Code:    http.StatusServiceUnavailable,
+			Success: false,
+			Message: "File storage is not configured",
+			Data:    nil,
+		})
+		return
+	}
+
+	var order models.Order
+	if err := config.DB.First(&order, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, response.DefaultResponse{
+			Code:    http.StatusNotFound,
+			Success: false,
+			Message: "Order not found",
+			Data:    nil,
+		})
+		return
+	}
+	if !containsStatus(allowed, order.Status) {
+		c.JSON(http.StatusConflict, response.DefaultResponse{
+			Code:    http.StatusConflict,
+			Success: false,
+			Message: fmt.Sprintf("Cannot upload %s proof while order is %s", stage, order.Status),
+			Data:    nil,
+		})
+		return
+	}
+
+	// Seluruh body dibatasi sebelum diparse, ditambah 1 MB untuk field dan
+	// header multipart
+	maxSize := maxProofSize()
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, int64(maxProofPhotos+1)*maxSize+1<<20)
+	form, err := c.MultipartForm()
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		c.JSON(http.StatusRequestEntityTooLarge, response.DefaultResponse{
+			Code:    http.StatusRequestEntityTooLarge,
+			Success: false,
+			Message: fmt.Sprintf("Upload is too large, each file may be at most %d MB", maxSize>>20),
+			Data:    nil,
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.DefaultResponse{
+			Code:    http.StatusBadRequest,
+			Success: false,
+			Message: "Proof must be sent as multipart form data",
+			Data:    nil,
+		})
+		return
+	}
+	photos, signatures := form.File["photos"], form.File["signature"]
+	if len(photos) == 0 || len(photos) > maxProofPhotos || len(signatures) > 1 {
+		c.JSON(http.StatusBadRequest, response.DefaultResponse{
+			Code:    http.StatusBadRequest,
+			Success: false,
+			Message: fmt.Sprintf("Upload between 1 and %d photos and at most one signature", maxProofPhotos),
+			Data:    nil,
+		})
+		return
+	}
+
+	actor := lifecycle.ActorFromContext(c)
+	recipient := strings.TrimSpace(c.PostForm("recipient_name"))
+	var proofs []models.OrderProof
+	upload := func(header *multipart.FileHeader, kind string) error {
+		proof, err := saveProofFile(files, header, order.ID, stage, maxSize)
+		if err != nil {
+			return err
+		}
+		proof.Kind = kind
+		proof.RecipientName = recipient
+		proof.UploadedByID = actor.UserID
+		proofs = append(proofs, proof)
+		return nil
+	}
+	// Hapus file yang sudah tersimpan jika salah satu langkah gagal
+	discard := func() {
+		for _, proof := range proofs {
+			if err := files.Delete(proof.StorageKey); err != nil {
+				log.Printf("storage: failed to delete %s: %v", proof.StorageKey, err)
+			}
+		}
+	}
+
+	for _, header := range photos {
+		if err = upload(header, models.ProofKindPhoto); err != nil {
+			break
+		}
+	}
+	if err == nil && len(signatures) == 1 {
+		err = upload(signatures[0], models.ProofKindSignature)
+	}
+	if err != nil {
+		discard()
+		status := http.StatusBadRequest
+		if errors.Is(err, errStoreProof) {
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, response.DefaultResponse{
+			Code:    status,
+			Success: false,
+			Message: err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+
+	// Bukti dilampirkan pada status terakhir order agar tampil di riwayat
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		var event models.OrderStatusEvent
+		if err := tx.Where("order_id = ?", order.ID).Order("created_at desc, id desc").Limit(1).Find(&event).Error; err != nil {
+			return err
+		}
+		for i := range proofs {
+			if event.ID != 0 {
+				proofs[i].StatusEventID = &event.ID
+			}
+		}
+		return tx.Create(&proofs).Error
+	})
+	if err != nil {
+		discard()
+		c.JSON(http.StatusInternalServerError, response.DefaultResponse{
+			Code:    http.StatusInternalServerError,
+			Success: false,
+			Message: "Failed to save proof",
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.DefaultResponse{
+		Code:    http.StatusOK,
+		Success: true,
+		Message: "Proof uploaded successfully",
+		Data:    response.NewOrderProofs(proofs),
+	})
+}
+
+// saveProofFile checks that header is an accepted image of at most maxSize
+// bytes and stores it
+func saveProofFile(files storage.Storage, header *multipart.FileHeader, orderID uint, stage string, maxSize int64) (models.OrderProof, error) {
+	if header.Size > maxSize {
+		return models.OrderProof{}, fmt.Errorf("%s is larger than %d MB", header.Filename, maxSize>>20)
+	}
+	file, err := header.Open()
+	if err != nil {
+		return models.OrderProof{}, fmt.Errorf("cannot read %s", header.Filename)
+	}
+	defer file.Close()
+
+	// Jenis file ditentukan dari isinya, bukan dari nama atau header klien
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return models.OrderProof{}, fmt.Errorf("cannot read %s", header.Filename)
+	}
+	head = head[:n]
+	contentType := http.DetectContentType(head)
+	ext, ok := proofExtensions[contentType]
+	if !ok {
+		return models.OrderProof{}, fmt.Errorf("%s must be a JPEG, PNG or WebP image", header.Filename)
+	}
+
+	key := storage.NewKey(fmt.Sprintf("orders/%d/%s", orderID, stage), ext)
+	if err := files.Save(key, io.MultiReader(bytes.NewReader(head), file)); err != nil {
+		log.Printf("storage: failed to save %s: %v", key, err)
+		return models.OrderProof{}, errStoreProof
+	}
+	return models.OrderProof{
+		OrderID:     orderID,
+		Stage:       stage,
+		StorageKey:  key,
+		ContentType: contentType,
+		Size:        header.Size,
+	}, nil
+}
+
+func containsStatus(statuses []models.OrderStatus, status models.OrderStatus) bool {
+	for _, candidate := range statuses {
+		if candidate == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package courier_controllers
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raihansyahrin/backend_laundry_app.git/config"
+	"github.com/raihansyahrin/backend_laundry_app.git/lifecycle"
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"github.com/raihansyahrin/backend_laundry_app.git/response"
+)
+
+// Status order yang masih menunggu penjemputan atau pengantaran oleh kurir
+var (
+	pickupStatuses   = []models.OrderStatus{models.OrderStatusCourierOnTheWay, models.OrderStatusArrived, models.OrderStatusWaitingForPayment}
+	deliveryStatuses = []models.OrderStatus{models.OrderStatusInProgress, models.OrderStatusDone, models.OrderStatusDelivering}
+)
+
+// GetCourierSchedule lists the pickups and deliveries of the logged-in
+// courier, earliest slot first. Jobs without a slot come last.
+func GetCourierSchedule(c *gin.Context) {
+	actor := lifecycle.ActorFromContext(c)
+
+	var orders []models.Order
+	err := config.DB.Preload("Address").Preload("PickupSlot").Preload("DeliverySlot").
+		Where("courier_id = ? AND status IN ?", actor.UserID, append(append([]models.OrderStatus{}, pickupStatuses...), deliveryStatuses...)).
+		Find(&orders).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.DefaultResponse{
+			Code:    http.StatusInternalServerError,
+			Success: false,
+			Message: "Failed to retrieve schedule",
+			Data:    nil,
+		})
+		return
+	}
+
+	entries := []response.ScheduleEntryResponse{}
+	for _, order := range orders {
+		entry := response.ScheduleEntryResponse{
+			OrderID: order.ID,
+			Status:  string(order.Status),
+			Kind:    models.SlotKindPickup,
+			Slot:    response.NewTimeSlot(order.PickupSlot),
+			Address: response.NewAddress(order.Address),
+		}
+		for _, status := range deliveryStatuses {
+			if order.Status == status {
+				entry.Kind = models.SlotKindDelivery
+				entry.Slot = response.NewTimeSlot(order.DeliverySlot)
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i].Slot, entries[j].Slot
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		return a.StartTime < b.StartTime
+	})
+
+	c.JSON(http.StatusOK, response.DefaultResponse{
+		Code:    http.StatusOK,
+		Success: true,
+		Message: "Schedule retrieved successfully",
+		Data:    entries,
+	})
+}
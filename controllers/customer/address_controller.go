@@ -29,6 +29,11 @@ func (ac *AddressController) CreateAddress(c *gin.Context) {
 	// Mengisi customer_id
 	address.CustomerID = customerID.(uint)
 
+	if !address.ValidCoordinates() {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Latitude and longitude must be given together and be valid coordinates"})
+		return
+	}
+
 	if err := config.DB.Create(&address).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to create address"})
 		return
@@ -48,6 +53,8 @@ func (ac *AddressController) CreateAddress(c *gin.Context) {
 		SubDistrict:   address.SubDistrict,
 		City:          address.City, // Gunakan nilai City dari model Address
 		Area:          address.Area, // Gunakan nilai Area dari model Address
+		Latitude:      address.Latitude,
+		Longitude:     address.Longitude,
 	}
 
 	c.JSON(http.StatusOK, response.DefaultResponse{
@@ -86,6 +93,8 @@ func (ac *AddressController) GetAddressesByUserID(c *gin.Context) {
 			SubDistrict:   address.SubDistrict,
 			City:          "Bandung",
 			Area:          "Bojongsoang",
+			Latitude:      address.Latitude,
+			Longitude:     address.Longitude,
 		}
 		if address.City != "" {
 			addressResponse.City = address.City
@@ -114,10 +123,18 @@ func (ac *AddressController) UpdateAddress(c *gin.Context) {
 		return
 	}
 
+	id, customerID := address.ID, address.CustomerID
 	if err := c.ShouldBindJSON(&address); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input format"})
 		return
 	}
+	// ID dan pemilik alamat tidak boleh diubah lewat body request
+	address.ID, address.CustomerID = id, customerID
+
+	if !address.ValidCoordinates() {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Latitude and longitude must be given together and be valid coordinates"})
+		return
+	}
 
 	if err := config.DB.Save(&address).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to update address"})
@@ -1,17 +1,29 @@
 package customer_controller
 
 import (
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/raihansyahrin/backend_laundry_app.git/config"
+	"github.com/raihansyahrin/backend_laundry_app.git/dispatch"
+	"github.com/raihansyahrin/backend_laundry_app.git/lifecycle"
 	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"github.com/raihansyahrin/backend_laundry_app.git/pricing"
+	"github.com/raihansyahrin/backend_laundry_app.git/processing"
 	"github.com/raihansyahrin/backend_laundry_app.git/response"
+	"github.com/raihansyahrin/backend_laundry_app.git/scheduling"
+	"gorm.io/gorm"
 )
 
 func GetOrderDetailForCustomer(c *gin.Context) {
-	customerIDStr := c.Param("customer_id")
+	// Route parameter adalah customer ID, baik di /api/customers/:id/orders
+	// maupun di alias lama /api/orders/:id
+	customerIDStr := c.Param("id")
 	customerID, err := strconv.Atoi(customerIDStr)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, response.DefaultResponse{
@@ -24,7 +36,7 @@ func GetOrderDetailForCustomer(c *gin.Context) {
 
 	// Fetch orders by customer ID
 	var orders []models.Order
-	if err := config.DB.Preload("Customer").Preload("Courier").Preload("Admin").Preload("Service").Preload("Address").Where("customer_id = ?", customerID).Find(&orders).Error; err != nil {
+	if err := config.DB.Preload("Customer").Preload("Courier").Preload("Admin").Preload("Service").Preload("Address").Preload("Addons").Preload("Charges").Preload("Items.Addons").Preload("Payments").Preload("Refunds").Preload("PickupSlot").Preload("DeliverySlot").Preload("StageEvents").Where("customer_id = ?", customerID).Find(&orders).Error; err != nil {
 		c.JSON(http.StatusBadRequest, response.DefaultResponse{
 			Success: false,
 			Message: "Invalid customer ID or no orders found",
@@ -42,16 +54,41 @@ func GetOrderDetailForCustomer(c *gin.Context) {
 		return
 	}
 
+	stages, err := processing.Stages(config.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.DefaultResponse{
+			Success: false,
+			Message: "Failed to retrieve processing stages",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
 	var orderResponses []response.OrderResponse
 	for _, order := range orders {
 		orderResponse := response.OrderResponse{
-			ID:         order.ID,
-			Status:     order.Status,
-			TotalPrice: order.TotalPrice,
-			Weight:     order.Weight,
-			Quantity:   order.Quantity,
-			CreatedAt:  order.CreatedAt.Format("2006-01-02 15:04:05"),
-			UpdatedAt:  order.UpdatedAt.Format("2006-01-02 15:04:05"),
+			ID:                  order.ID,
+			Status:              string(order.Status),
+			Area:                order.Area,
+			Addons:              response.NewOrderAddons(order.Addons),
+			Items:               response.NewOrderItems(order.Items),
+			PriceBreakdown:      response.NewPriceBreakdown(order.Charges),
+			PromoCode:           order.PromoCode,
+			Discount:            order.Discount,
+			AmountDue:           order.AmountDue(),
+			NetPaid:             order.NetPaid(),
+			DeliveryFee:         order.DeliveryFee,
+			DeliveryFeeBasis:    order.DeliveryFeeBasis,
+			ProcessingStage:     order.ProcessingStage,
+			PickupSlot:          response.NewTimeSlot(order.PickupSlot),
+			DeliverySlot:        response.NewTimeSlot(order.DeliverySlot),
+			EstimatedReadyAt:    order.EstimatedReadyAt,
+			EstimatedDeliveryAt: order.EstimatedDeliveryAt,
+			TotalPrice:          order.TotalPrice,
+			Weight:              order.Weight,
+			Quantity:            order.Quantity,
+			CreatedAt:           order.CreatedAt.Format("2006-01-02 15:04:05"),
+			UpdatedAt:           order.UpdatedAt.Format("2006-01-02 15:04:05"),
 			Customer: response.UserResponse{
 				ID:       order.Customer.ID,
 				Username: order.Customer.Username,
@@ -70,6 +107,8 @@ func GetOrderDetailForCustomer(c *gin.Context) {
 				SubDistrict:   order.Address.SubDistrict,
 				City:          order.Address.City,
 				Area:          order.Address.Area,
+				Latitude:      order.Address.Latitude,
+				Longitude:     order.Address.Longitude,
 			},
 			Courier: response.UserResponse{
 				ID:       order.Courier.ID,
@@ -85,8 +124,17 @@ func GetOrderDetailForCustomer(c *gin.Context) {
 				ID:    order.Service.ID,
 				Title: order.Service.Title,
 				Price: uint(order.Service.Price),
+				Unit:  order.ServiceUnit,
 			},
 		}
+		// Progres pengerjaan di outlet ditampilkan sejak tahap pertama dimulai
+		if len(order.StageEvents) > 0 {
+			orderResponse.Progress = response.NewStageProgress(processing.Progress(stages, order.StageEvents))
+		}
+		// Kode hanya ditunjukkan selama pesanan sedang diantar
+		if order.Status == models.OrderStatusDelivering {
+			orderResponse.DeliveryCode = order.DeliveryCode
+		}
 		orderResponses = append(orderResponses, orderResponse)
 	}
 
@@ -98,10 +146,51 @@ func GetOrderDetailForCustomer(c *gin.Context) {
 	})
 }
 
+// orderItemRequest is one service of a new order with the add-ons chosen for it
+type orderItemRequest struct {
+	ServiceID uint   `json:"service_id"`
+	AddonIDs  []uint `json:"addon_ids"`
+}
+
+// bookSlots reserves the pickup and delivery slots chosen for a new order.
+// Delivery has to start after pickup has ended.
+func bookSlots(tx *gorm.DB, order *models.Order, area string) error {
+	now := time.Now()
+	var pickupEnd time.Time
+	if order.PickupSlotID != nil {
+		slot, err := scheduling.Book(tx, *order.PickupSlotID, models.SlotKindPickup, area, now)
+		if err != nil {
+			return err
+		}
+		if pickupEnd, err = slot.End(); err != nil {
+			return err
+		}
+	}
+	if order.DeliverySlotID != nil {
+		slot, err := scheduling.Book(tx, *order.DeliverySlotID, models.SlotKindDelivery, area, now)
+		if err != nil {
+			return err
+		}
+		start, err := slot.Start()
+		if err != nil {
+			return err
+		}
+		if start.Before(pickupEnd) {
+			return fmt.Errorf("%w: delivery must start after the pickup window", scheduling.ErrSlotUnavailable)
+		}
+	}
+	return nil
+}
+
 func CreateOrder(c *gin.Context) {
 	var body struct {
-		ServiceID uint `json:"service_id" form:"service_id"`
-		AddressID uint `json:"address_id" form:"address_id"`
+		ServiceID uint               `json:"service_id" form:"service_id"`
+		AddressID uint               `json:"address_id" form:"address_id"`
+		AddonIDs  []uint             `json:"addon_ids" form:"addon_ids"`
+		Items     []orderItemRequest `json:"items"`
+
+		PickupSlotID   *uint `json:"pickup_slot_id" form:"pickup_slot_id"`
+		DeliverySlotID *uint `json:"delivery_slot_id" form:"delivery_slot_id"`
 	}
 
 	if err := c.ShouldBind(&body); err != nil {
@@ -135,44 +224,139 @@ func CreateOrder(c *gin.Context) {
 		}
 	}
 
-	// Ambil service dari database
-	var service models.Service
-	if err := config.DB.First(&service, body.ServiceID).Error; err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid service ID"})
+	// Alamat harus berada di salah satu area layanan outlet
+	if err := dispatch.CheckServiceArea(config.DB, address); err != nil {
+		if errors.Is(err, dispatch.ErrAddressWithoutLocation) || errors.Is(err, dispatch.ErrOutsideServiceArea) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"message": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to check service area"})
 		return
 	}
 
-	// Calculate total price based on service price and any other applicable factors
-	// totalPrice := service.Price // Adjust calculation as needed
+	// Satu order bisa berisi beberapa layanan, service_id tunggal tetap didukung
+	if len(body.Items) == 0 && body.ServiceID != 0 {
+		body.Items = append(body.Items, orderItemRequest{ServiceID: body.ServiceID, AddonIDs: body.AddonIDs})
+	}
+	if len(body.Items) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Please choose at least one service"})
+		return
+	}
+
+	var items []models.OrderItem
+	for _, requested := range body.Items {
+		// Ambil service dari database
+		var service models.Service
+		if err := config.DB.Preload("Addons").First(&service, requested.ServiceID).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid service ID"})
+			return
+		}
+
+		// Simpan judul, unit dan harga layanan saat ini agar perubahan katalog tidak mengubah order
+		var item models.OrderItem
+		item.SnapshotService(service)
+
+		// Add-on yang dipilih harus milik layanan yang dipesan
+		for _, addonID := range requested.AddonIDs {
+			var found *models.ServiceAddon
+			for i := range service.Addons {
+				if service.Addons[i].ID == addonID {
+					found = &service.Addons[i]
+				}
+			}
+			if found == nil {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid add-on ID for this service"})
+				return
+			}
+			item.Addons = append(item.Addons, models.OrderAddon{
+				ServiceAddonID: found.ID,
+				Code:           found.Code,
+				Name:           found.Name,
+				Price:          found.Price,
+				PerUnit:        found.PerUnit,
+			})
+		}
+		items = append(items, item)
+	}
 
 	// Buat order baru tanpa courier_id dan weight
 	adminID := uint(1)
 	order := models.Order{
 		CustomerID: customerID.(uint),
 		AdminID:    &adminID, // Adjust as needed
-		ServiceID:  body.ServiceID,
+		ServiceID:  items[0].ServiceID,
 		AddressID:  body.AddressID,
-		// TotalPrice: totalPrice,
-		Status: "waiting for courier approval",
+
+		PickupSlotID:   body.PickupSlotID,
+		DeliverySlotID: body.DeliverySlotID,
 	}
 
-	if err := config.DB.Create(&order).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to create order", "error": err.Error()})
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := bookSlots(tx, &order, address.Area); err != nil {
+			return err
+		}
+		// Ongkir awal, dihitung ulang setelah laundry ditimbang
+		quote, err := pricing.QuoteDelivery(tx, address, 0)
+		if err != nil {
+			return err
+		}
+		order.DeliveryFee, order.DeliveryFeeBasis = quote.Fee, quote.Basis
+		if err := lifecycle.Transition(tx, &order, models.OrderStatusWaitingForCourier, lifecycle.ActorFromContext(c)); err != nil {
+			return err
+		}
+		for i := range items {
+			items[i].OrderID = order.ID
+			for j := range items[i].Addons {
+				items[i].Addons[j].OrderID = order.ID
+			}
+		}
+		return tx.Create(&items).Error
+	})
+	if err != nil {
+		if errors.Is(err, scheduling.ErrSlotFull) {
+			c.JSON(http.StatusConflict, gin.H{"message": "The chosen time slot is full", "error": err.Error()})
+			return
+		}
+		if scheduling.IsSlotError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid time slot", "error": err.Error()})
+			return
+		}
+		c.JSON(lifecycle.ErrorStatus(err), gin.H{"message": "Failed to create order", "error": err.Error()})
 		return
 	}
 
+	// Tawarkan order ke kurir sesuai strategi dispatch, jika gagal order tetap di job board
+	if _, err := dispatch.AutoAssign(config.DB, &order, address, dispatch.Strategy()); err != nil {
+		log.Printf("dispatch: failed to assign order %d: %v", order.ID, err)
+	}
+
 	// Preload entitas terkait sebelum mengirimkan respons
-	if err := config.DB.Preload("Address").Preload("Customer").Preload("Admin").Preload("Service").First(&order, order.ID).Error; err != nil {
+	if err := config.DB.Preload("Address").Preload("Addons").Preload("Charges").Preload("Items.Addons").Preload("Payments").Preload("Refunds").Preload("PickupSlot").Preload("DeliverySlot").Preload("Customer").Preload("Admin").Preload("Service").First(&order, order.ID).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve created order with associated data", "error": err.Error()})
 		return
 	}
 
 	// Prepare response
 	orderResponse := response.OrderResponse{
-		ID:        order.ID,
-		Status:    order.Status,
-		CreatedAt: order.CreatedAt.String(),
-		UpdatedAt: order.UpdatedAt.String(),
+		ID:                  order.ID,
+		Status:              string(order.Status),
+		Area:                order.Area,
+		Addons:              response.NewOrderAddons(order.Addons),
+		Items:               response.NewOrderItems(order.Items),
+		PriceBreakdown:      response.NewPriceBreakdown(order.Charges),
+		PromoCode:           order.PromoCode,
+		Discount:            order.Discount,
+		AmountDue:           order.AmountDue(),
+		NetPaid:             order.NetPaid(),
+		DeliveryFee:         order.DeliveryFee,
+		DeliveryFeeBasis:    order.DeliveryFeeBasis,
+		ProcessingStage:     order.ProcessingStage,
+		PickupSlot:          response.NewTimeSlot(order.PickupSlot),
+		DeliverySlot:        response.NewTimeSlot(order.DeliverySlot),
+		EstimatedReadyAt:    order.EstimatedReadyAt,
+		EstimatedDeliveryAt: order.EstimatedDeliveryAt,
+		CreatedAt:           order.CreatedAt.String(),
+		UpdatedAt:           order.UpdatedAt.String(),
 		Customer: response.UserResponse{
 			ID:       order.Customer.ID,
 			Username: order.Customer.Username,
@@ -187,6 +371,7 @@ func CreateOrder(c *gin.Context) {
 			ID:    order.Service.ID,
 			Title: order.Service.Title,
 			Price: uint(order.Service.Price),
+			Unit:  order.ServiceUnit,
 		},
 		Address: response.AddressResponse{
 			ID:            order.Address.ID,
@@ -201,6 +386,8 @@ func CreateOrder(c *gin.Context) {
 			SubDistrict:   order.Address.SubDistrict,
 			City:          order.Address.City,
 			Area:          order.Address.Area,
+			Latitude:      order.Address.Latitude,
+			Longitude:     order.Address.Longitude,
 		},
 	}
 
@@ -1,41 +1,55 @@
 package customer_controller
 
 import (
-	"encoding/json"
-	"errors"
+	"log"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-resty/resty/v2"
 	"github.com/raihansyahrin/backend_laundry_app.git/config"
+	"github.com/raihansyahrin/backend_laundry_app.git/lifecycle"
 	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"github.com/raihansyahrin/backend_laundry_app.git/payment"
+	"gorm.io/gorm"
 )
 
-func generateQRCode(order models.Order) (string, error) {
-	client := resty.New()
-	// Replace with your payment gateway API URL and parameters
-	resp, err := client.R().
-		SetBody(map[string]interface{}{
-			"amount":      order.TotalPrice,
-			"description": "Payment for order " + string(order.ID),
-		}).
-		Post("https://api.example.com/generate_qr")
-	if err != nil {
-		return "", err
+// createQRISCharge records a payment attempt and asks the QRIS provider for
+// a QR code. Failed attempts are kept with the provider's error.
+func createQRISCharge(c *gin.Context, provider payment.Provider, order models.Order) (*models.Payment, error) {
+	record := models.Payment{
+		OrderID:   order.ID,
+		Method:    models.PaymentMethodQRIS,
+		Provider:  provider.Name(),
+		Reference: payment.NewReference(order.ID),
+		Amount:    order.AmountDue(),
+		Status:    models.PaymentStatusPending,
+	}
+	if err := config.DB.Create(&record).Error; err != nil {
+		return nil, err
 	}
 
-	var result map[string]interface{}
-	err = json.Unmarshal(resp.Body(), &result)
+	charge, err := provider.CreateCharge(c.Request.Context(), payment.ChargeRequest{
+		OrderID:     order.ID,
+		Reference:   record.Reference,
+		Amount:      record.Amount,
+		Description: "Payment for order " + strconv.FormatUint(uint64(order.ID), 10),
+	})
 	if err != nil {
-		return "", err
+		record.Status = models.PaymentStatusFailed
+		record.RawResponse = err.Error()
+		config.DB.Save(&record)
+		return nil, err
 	}
 
-	qrCode, ok := result["qr_code"].(string)
-	if !ok {
-		return "", errors.New("failed to parse QR code from response")
+	record.ProviderReference = charge.Reference
+	record.Status = string(charge.Status)
+	record.QRCode = charge.QRCode
+	record.ExpiresAt = charge.ExpiresAt
+	record.RawResponse = charge.Raw
+	if err := config.DB.Save(&record).Error; err != nil {
+		return nil, err
 	}
-
-	return qrCode, nil
+	return &record, nil
 }
 
 func ProcessPayment(c *gin.Context) {
@@ -55,25 +69,75 @@ func ProcessPayment(c *gin.Context) {
 		return
 	}
 
-	if body.Method == "cash" {
-		order.Status = "completed"
-		if err := config.DB.Save(&order).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to update order status"})
+	actor := lifecycle.ActorFromContext(c)
+
+	if body.Method == models.PaymentMethodCash {
+		var collectedByID *uint
+		if actor.Role == models.RoleCourier {
+			collectedByID = &actor.UserID
+		}
+		err := config.DB.Transaction(func(tx *gorm.DB) error {
+			if err := lifecycle.Transition(tx, &order, models.OrderStatusCompleted, actor); err != nil {
+				return err
+			}
+			// Uang yang sudah diterima kurir atau lewat QRIS tidak dicatat lagi,
+			// hanya kekurangannya yang dibayar tunai sekarang
+			if err := tx.Where("order_id = ?", order.ID).Find(&order.Payments).Error; err != nil {
+				return err
+			}
+			shortfall := order.AmountDue() - order.PaidAmount()
+			if shortfall <= 0 {
+				return nil
+			}
+			record := models.NewCashPayment(&order, payment.NewReference(order.ID), collectedByID)
+			record.Amount = shortfall
+			return tx.Create(&record).Error
+		})
+		if err != nil {
+			c.JSON(lifecycle.ErrorStatus(err), gin.H{"message": "Failed to update order status", "error": err.Error()})
 			return
 		}
 		c.JSON(http.StatusOK, gin.H{"message": "Order marked as paid with cash"})
-	} else if body.Method == "qris" {
-		qrCode, err := generateQRCode(order)
+	} else if body.Method == models.PaymentMethodQRIS {
+		// Tolak lebih awal agar QR code tidak dibuat untuk order yang tidak bisa dibayar
+		if err := lifecycle.Check(order.Status, models.OrderStatusWaitingForPayment, actor.Role); err != nil {
+			c.JSON(lifecycle.ErrorStatus(err), gin.H{"message": "Failed to update order status", "error": err.Error()})
+			return
+		}
+		if order.AmountDue() <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Order has nothing to pay"})
+			return
+		}
+
+		provider, ok := payment.ForMethod(models.PaymentMethodQRIS)
+		if !ok {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"message": "QRIS payment is not available"})
+			return
+		}
+
+		record, err := createQRISCharge(c, provider, order)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to generate QR code", "error": err.Error()})
+			c.JSON(http.StatusBadGateway, gin.H{"message": "Failed to generate QR code", "error": err.Error()})
 			return
 		}
-		order.Status = "waiting for payment confirmation"
-		if err := config.DB.Save(&order).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to update order status"})
+		if err := lifecycle.Transition(config.DB, &order, models.OrderStatusWaitingForPayment, actor); err != nil {
+			// QR code yang sudah dibuat tidak boleh bisa dibayar untuk order yang tidak menunggu pembayaran
+			if err := config.DB.Model(&models.Payment{}).
+				Where("id = ? AND status = ?", record.ID, models.PaymentStatusPending).
+				Update("status", models.PaymentStatusExpired).Error; err != nil {
+				log.Printf("payment: failed to expire %s: %v", record.Reference, err)
+			}
+			payment.CancelCharges(c.Request.Context(), []models.Payment{*record})
+			c.JSON(lifecycle.ErrorStatus(err), gin.H{"message": "Failed to update order status", "error": err.Error()})
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"message": "QRIS payment initiated", "qr_code": qrCode})
+		c.JSON(http.StatusOK, gin.H{
+			"message":    "QRIS payment initiated",
+			"qr_code":    record.QRCode,
+			"payment_id": record.ID,
+			"amount":     record.Amount,
+			"expires_at": record.ExpiresAt,
+		})
 	} else {
 		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid payment method"})
 	}
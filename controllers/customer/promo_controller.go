@@ -0,0 +1,178 @@
+package customer_controller
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raihansyahrin/backend_laundry_app.git/config"
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"github.com/raihansyahrin/backend_laundry_app.git/pricing"
+	"github.com/raihansyahrin/backend_laundry_app.git/response"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// promoEditable reports whether the promo of an order can still change,
+// i.e. the customer has not started paying yet
+func promoEditable(order models.Order) bool {
+	switch order.Status {
+	case models.OrderStatusWaitingForCourier, models.OrderStatusCourierOnTheWay, models.OrderStatusArrived:
+		return true
+	}
+	return false
+}
+
+// errPromoNotEditable is returned when the order moved on to payment
+var errPromoNotEditable = errors.New("promo codes can only be changed before payment")
+
+// lockPromoEditable locks the order row for the rest of tx and checks on the
+// locked row that its promo can still change, so the order cannot move to
+// payment while the promo is being changed
+func lockPromoEditable(tx *gorm.DB, order *models.Order) error {
+	var current models.Order
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "status", "promo_id").First(&current, order.ID).Error; err != nil {
+		return err
+	}
+	order.Status, order.PromoID = current.Status, current.PromoID
+	if !promoEditable(*order) {
+		return errPromoNotEditable
+	}
+	return nil
+}
+
+// ApplyPromo memasang kode promo ke order milik customer
+func ApplyPromo(c *gin.Context) {
+	var body struct {
+		Code string `json:"code" form:"code"`
+	}
+
+	if err := c.ShouldBind(&body); err != nil || body.Code == "" {
+		c.JSON(http.StatusBadRequest, response.DefaultResponse{
+			Code:    http.StatusBadRequest,
+			Success: false,
+			Message: "Invalid input format",
+		})
+		return
+	}
+
+	var order models.Order
+	if err := config.DB.Preload("Items").First(&order, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, response.DefaultResponse{
+			Code:    http.StatusNotFound,
+			Success: false,
+			Message: "Order not found",
+		})
+		return
+	}
+
+	if !promoEditable(order) {
+		c.JSON(http.StatusConflict, response.DefaultResponse{
+			Code:    http.StatusConflict,
+			Success: false,
+			Message: "Promo codes can only be changed before payment",
+		})
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockPromoEditable(tx, &order); err != nil {
+			return err
+		}
+		return pricing.ApplyPromo(tx, &order, body.Code, time.Now())
+	})
+	if errors.Is(err, errPromoNotEditable) {
+		c.JSON(http.StatusConflict, response.DefaultResponse{
+			Code:    http.StatusConflict,
+			Success: false,
+			Message: "Promo codes can only be changed before payment",
+		})
+		return
+	}
+	if pricing.IsPromoError(err) {
+		c.JSON(http.StatusBadRequest, response.DefaultResponse{
+			Code:    http.StatusBadRequest,
+			Success: false,
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.DefaultResponse{
+			Code:    http.StatusInternalServerError,
+			Success: false,
+			Message: "Failed to apply promo code",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.DefaultResponse{
+		Code:    http.StatusOK,
+		Success: true,
+		Message: "Promo code applied",
+		Data: gin.H{
+			"order_id":    order.ID,
+			"promo_code":  order.PromoCode,
+			"total_price": order.TotalPrice,
+			"discount":    order.Discount,
+			"amount_due":  order.AmountDue(),
+		},
+	})
+}
+
+// RemovePromo melepas kode promo dari order milik customer
+func RemovePromo(c *gin.Context) {
+	var order models.Order
+	if err := config.DB.First(&order, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, response.DefaultResponse{
+			Code:    http.StatusNotFound,
+			Success: false,
+			Message: "Order not found",
+		})
+		return
+	}
+
+	if !promoEditable(order) {
+		c.JSON(http.StatusConflict, response.DefaultResponse{
+			Code:    http.StatusConflict,
+			Success: false,
+			Message: "Promo codes can only be changed before payment",
+		})
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockPromoEditable(tx, &order); err != nil {
+			return err
+		}
+		return pricing.RemovePromo(tx, &order)
+	})
+	if errors.Is(err, errPromoNotEditable) {
+		c.JSON(http.StatusConflict, response.DefaultResponse{
+			Code:    http.StatusConflict,
+			Success: false,
+			Message: "Promo codes can only be changed before payment",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.DefaultResponse{
+			Code:    http.StatusInternalServerError,
+			Success: false,
+			Message: "Failed to remove promo code",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.DefaultResponse{
+		Code:    http.StatusOK,
+		Success: true,
+		Message: "Promo code removed",
+		Data: gin.H{
+			"order_id":    order.ID,
+			"total_price": order.TotalPrice,
+			"amount_due":  order.AmountDue(),
+		},
+	})
+}
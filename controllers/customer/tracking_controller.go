@@ -0,0 +1,98 @@
+package customer_controller
+
+import (
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raihansyahrin/backend_laundry_app.git/config"
+	"github.com/raihansyahrin/backend_laundry_app.git/geo"
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"github.com/raihansyahrin/backend_laundry_app.git/response"
+	"github.com/raihansyahrin/backend_laundry_app.git/tracking"
+)
+
+// GetCourierLocation returns the last known position of the courier of an
+// order and, when the address has coordinates, a straight-line ETA
+func GetCourierLocation(c *gin.Context) {
+	var order models.Order
+	if err := config.DB.Preload("Address").First(&order, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, response.DefaultResponse{
+			Code:    http.StatusNotFound,
+			Success: false,
+			Message: "Order not found",
+			Data:    nil,
+		})
+		return
+	}
+
+	tracked := false
+	for _, status := range tracking.TrackedStatuses {
+		if order.Status == status {
+			tracked = true
+		}
+	}
+	if !tracked || order.CourierID == nil {
+		c.JSON(http.StatusConflict, response.DefaultResponse{
+			Code:    http.StatusConflict,
+			Success: false,
+			Message: "Courier is not on the way for this order",
+			Data:    nil,
+		})
+		return
+	}
+
+	now := time.Now()
+	since, err := tracking.TrackedSince(config.DB, order)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.DefaultResponse{
+			Code:    http.StatusInternalServerError,
+			Success: false,
+			Message: "Failed to retrieve courier location",
+			Data:    nil,
+		})
+		return
+	}
+	location, err := tracking.Latest(config.DB, *order.CourierID, since, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.DefaultResponse{
+			Code:    http.StatusInternalServerError,
+			Success: false,
+			Message: "Failed to retrieve courier location",
+			Data:    nil,
+		})
+		return
+	}
+	if location == nil {
+		c.JSON(http.StatusNotFound, response.DefaultResponse{
+			Code:    http.StatusNotFound,
+			Success: false,
+			Message: "Courier location is not available yet",
+			Data:    nil,
+		})
+		return
+	}
+
+	data := response.CourierLocationResponse{
+		OrderID:    order.ID,
+		Status:     string(order.Status),
+		Latitude:   location.Latitude,
+		Longitude:  location.Longitude,
+		RecordedAt: location.RecordedAt,
+	}
+	if destination, ok := order.Address.Point(); ok {
+		distance := geo.DistanceKm(geo.Point{Lat: location.Latitude, Lng: location.Longitude}, destination)
+		distance = math.Round(distance*100) / 100
+		minutes := int(math.Ceil(tracking.ETA(distance).Minutes()))
+		data.DistanceKm = &distance
+		data.ETAMinutes = &minutes
+	}
+
+	c.JSON(http.StatusOK, response.DefaultResponse{
+		Code:    http.StatusOK,
+		Success: true,
+		Message: "Courier location retrieved successfully",
+		Data:    data,
+	})
+}
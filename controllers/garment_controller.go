@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raihansyahrin/backend_laundry_app.git/config"
+	"github.com/raihansyahrin/backend_laundry_app.git/garments"
+	"github.com/raihansyahrin/backend_laundry_app.git/lifecycle"
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"github.com/raihansyahrin/backend_laundry_app.git/response"
+)
+
+// garmentInput is one garment in the body of RegisterGarments
+type garmentInput struct {
+	Tag       string `json:"tag"`
+	Type      string `json:"type"`
+	Colour    string `json:"colour"`
+	Brand     string `json:"brand"`
+	Condition string `json:"condition"`
+	Notes     string `json:"notes"`
+}
+
+// GetGarments lists the tagged garments of an order
+func GetGarments(c *gin.Context) {
+	var list []models.Garment
+	if err := config.DB.Where("order_id = ?", c.Param("id")).Order("id").Find(&list).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve garments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": response.NewGarments(list), "code": 200, "success": true})
+}
+
+// RegisterGarments tags the garments of an order at intake, by the courier
+// at pickup or by the outlet when the laundry arrives
+func RegisterGarments(c *gin.Context) {
+	var body struct {
+		Garments []garmentInput `json:"garments"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || len(body.Garments) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Please list at least one garment"})
+		return
+	}
+
+	var list []models.Garment
+	for _, input := range body.Garments {
+		garment := models.Garment{
+			Tag:       strings.TrimSpace(input.Tag),
+			Type:      strings.TrimSpace(input.Type),
+			Colour:    strings.TrimSpace(input.Colour),
+			Brand:     strings.TrimSpace(input.Brand),
+			Condition: strings.TrimSpace(input.Condition),
+			Notes:     strings.TrimSpace(input.Notes),
+		}
+		if garment.Tag == "" || len(garment.Tag) > 64 || garment.Type == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Every garment needs a tag of at most 64 characters and a type"})
+			return
+		}
+		list = append(list, garment)
+	}
+
+	var order models.Order
+	if err := config.DB.First(&order, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Order not found"})
+		return
+	}
+
+	if err := garments.Register(config.DB, order, list, lifecycle.ActorFromContext(c).UserID); err != nil {
+		var wrongOrder *garments.WrongOrderError
+		if errors.As(err, &wrongOrder) {
+			c.JSON(http.StatusConflict, gin.H{"message": err.Error(), "order_id": wrongOrder.OrderID})
+			return
+		}
+		if garments.IsGarmentError(err) {
+			c.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to register garments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"success": true,
+		"message": "Garments registered successfully",
+		"data":    response.NewGarments(list),
+	})
+}
+
+// ScanGarment records a garment tag being scanned at a processing stage. A
+// tag from another order is reported with the order it belongs to.
+func ScanGarment(c *gin.Context) {
+	var body struct {
+		Tag   string `json:"tag" form:"tag"`
+		Stage string `json:"stage" form:"stage"`
+	}
+	if err := c.ShouldBind(&body); err != nil || strings.TrimSpace(body.Tag) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Tag is required"})
+		return
+	}
+	stage := garments.NormalizeStage(body.Stage)
+	if stage == "" || stage == models.GarmentStageIntake || len(stage) > 50 {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Stage is required, garments are taken in by registering them"})
+		return
+	}
+
+	var order models.Order
+	if err := config.DB.First(&order, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Order not found"})
+		return
+	}
+
+	garment, err := garments.Scan(config.DB, order, body.Tag, stage, lifecycle.ActorFromContext(c).UserID)
+	var wrongOrder *garments.WrongOrderError
+	if errors.As(err, &wrongOrder) {
+		c.JSON(http.StatusConflict, gin.H{"message": err.Error(), "order_id": wrongOrder.OrderID})
+		return
+	}
+	if errors.Is(err, garments.ErrUnknownTag) {
+		c.JSON(http.StatusNotFound, gin.H{"message": err.Error()})
+		return
+	}
+	if garments.IsGarmentError(err) {
+		c.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to record scan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"success": true,
+		"message": "Garment scanned",
+		"data":    response.NewGarment(*garment),
+	})
+}
+
+// GetGarmentReport compares the number of garments taken in for an order
+// with the number scanned for delivery
+func GetGarmentReport(c *gin.Context) {
+	var order models.Order
+	if err := config.DB.First(&order, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Order not found"})
+		return
+	}
+
+	report, err := garments.OrderReport(config.DB, order)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to build garment report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"order_id":        report.OrderID,
+		"status":          report.Status,
+		"quantity":        report.Quantity,
+		"intake_count":    report.IntakeCount,
+		"delivered_count": report.DeliveredCount,
+		"mismatch":        report.Mismatch,
+		"missing":         response.NewGarments(report.Missing),
+	}, "code": 200, "success": true})
+}
+
+// GetGarmentMismatches lists delivered orders that came back with a
+// different number of garments than were taken in
+func GetGarmentMismatches(c *gin.Context) {
+	mismatches, err := garments.Mismatches(config.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to retrieve garment mismatches"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": mismatches, "code": 200, "success": true})
+}
@@ -1,14 +1,41 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/raihansyahrin/backend_laundry_app.git/config"
+	"github.com/raihansyahrin/backend_laundry_app.git/lifecycle"
 	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"github.com/raihansyahrin/backend_laundry_app.git/payment"
+	"github.com/raihansyahrin/backend_laundry_app.git/processing"
 	"github.com/raihansyahrin/backend_laundry_app.git/response"
+	"github.com/raihansyahrin/backend_laundry_app.git/scheduling"
+	"github.com/raihansyahrin/backend_laundry_app.git/storage"
+	"gorm.io/gorm"
 )
 
+// processingMoves are the only moves UpdateOrderStatus makes, because they
+// change nothing but the status. Every other move has side effects, such as
+// pricing, a payment or the delivery code, and goes through its own endpoint.
+var processingMoves = map[models.OrderStatus][]models.OrderStatus{
+	models.OrderStatusInProgress: {models.OrderStatusDone},
+}
+
+func isProcessingMove(from, to models.OrderStatus) bool {
+	for _, allowed := range processingMoves[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateOrderStatus makes one of the processingMoves, e.g. marking laundry
+// in progress as done
 func UpdateOrderStatus(c *gin.Context) {
 	var body struct {
 		OrderID uint   `json:"order_id" form:"order_id"`
@@ -26,16 +53,35 @@ func UpdateOrderStatus(c *gin.Context) {
 		return
 	}
 
-	// Check if the status update is valid
-	if body.Status == "in progress" && order.Status != "arrived" {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid status update"})
+	// Pembatalan harus lewat /orders/:id/cancel agar kurir dilepas dan dana dikembalikan
+	if models.OrderStatus(body.Status) == models.OrderStatusCancelled {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Use the cancel endpoint to cancel an order"})
+		return
+	}
+	// Pengantaran dikonfirmasi dengan kode dari customer lewat /orders/:id/delivered
+	if models.OrderStatus(body.Status) == models.OrderStatusDelivered {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Use the delivered endpoint to confirm a delivery"})
+		return
+	}
+	if !isProcessingMove(order.Status, models.OrderStatus(body.Status)) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "This status change has its own endpoint, e.g. courier arrival, payment or delivery",
+			"error":   "cannot move order from " + string(order.Status) + " to " + body.Status + " here",
+		})
 		return
 	}
 
-	order.Status = body.Status
-
-	if err := config.DB.Save(&order).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to update order status"})
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lifecycle.Transition(tx, &order, models.OrderStatus(body.Status), lifecycle.ActorFromContext(c)); err != nil {
+			return err
+		}
+		if order.Status != models.OrderStatusDone {
+			return nil
+		}
+		return processing.Finish(tx, order.ID, time.Now())
+	})
+	if err != nil {
+		c.JSON(lifecycle.ErrorStatus(err), gin.H{"message": "Failed to update order status", "error": err.Error()})
 		return
 	}
 
@@ -44,7 +90,7 @@ func UpdateOrderStatus(c *gin.Context) {
 
 func GetOrders(c *gin.Context) {
 	var orders []models.Order
-	if err := config.DB.Preload("Customer").Preload("Courier").Preload("Admin").Preload("Service").Preload("Address").Find(&orders).Error; err != nil {
+	if err := config.DB.Preload("Customer").Preload("Courier").Preload("Admin").Preload("Service").Preload("Address").Preload("Addons").Preload("Charges").Preload("Items.Addons").Preload("Payments").Preload("Refunds").Preload("PickupSlot").Preload("DeliverySlot").Find(&orders).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, response.DefaultResponse{
 			Success: false,
 			Message: "Failed to retrieve orders",
@@ -56,10 +102,25 @@ func GetOrders(c *gin.Context) {
 	var orderResponses []response.OrderResponse
 	for _, order := range orders {
 		orderResponse := response.OrderResponse{
-			ID:        order.ID,
-			Status:    order.Status,
-			CreatedAt: order.CreatedAt.Format("2006-01-02 15:04:05"),
-			UpdatedAt: order.UpdatedAt.Format("2006-01-02 15:04:05"),
+			ID:                  order.ID,
+			Status:              string(order.Status),
+			Area:                order.Area,
+			Addons:              response.NewOrderAddons(order.Addons),
+			Items:               response.NewOrderItems(order.Items),
+			PriceBreakdown:      response.NewPriceBreakdown(order.Charges),
+			PromoCode:           order.PromoCode,
+			Discount:            order.Discount,
+			AmountDue:           order.AmountDue(),
+			NetPaid:             order.NetPaid(),
+			DeliveryFee:         order.DeliveryFee,
+			DeliveryFeeBasis:    order.DeliveryFeeBasis,
+			ProcessingStage:     order.ProcessingStage,
+			PickupSlot:          response.NewTimeSlot(order.PickupSlot),
+			DeliverySlot:        response.NewTimeSlot(order.DeliverySlot),
+			EstimatedReadyAt:    order.EstimatedReadyAt,
+			EstimatedDeliveryAt: order.EstimatedDeliveryAt,
+			CreatedAt:           order.CreatedAt.Format("2006-01-02 15:04:05"),
+			UpdatedAt:           order.UpdatedAt.Format("2006-01-02 15:04:05"),
 			Customer: response.UserResponse{
 				ID:        order.Customer.ID,
 				Username:  order.Customer.Username,
@@ -83,6 +144,7 @@ func GetOrders(c *gin.Context) {
 				ID:    order.Service.ID,
 				Title: order.Service.Title,
 				Price: uint(order.Service.Price),
+				Unit:  order.ServiceUnit,
 			},
 			Address: response.AddressResponse{
 				ID:            order.Address.ID,
@@ -97,6 +159,8 @@ func GetOrders(c *gin.Context) {
 				SubDistrict:   order.Address.SubDistrict,
 				City:          order.Address.City,
 				Area:          order.Address.Area,
+				Latitude:      order.Address.Latitude,
+				Longitude:     order.Address.Longitude,
 			},
 		}
 		orderResponses = append(orderResponses, orderResponse)
@@ -127,3 +191,155 @@ func DeleteOrder(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Order deleted successfully"})
 }
+
+// CancelOrder cancels an order. Customers may only cancel while no courier
+// has accepted it, admins may cancel later but must give a reason. The
+// courier is released and whatever was paid is refunded; the order itself is
+// kept for its history.
+func CancelOrder(c *gin.Context) {
+	var body struct {
+		Reason string `json:"reason" form:"reason"`
+	}
+	if err := c.ShouldBind(&body); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid input format"})
+		return
+	}
+	body.Reason = strings.TrimSpace(body.Reason)
+
+	actor := lifecycle.ActorFromContext(c)
+	if actor.Role == models.RoleAdmin && body.Reason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Reason is required"})
+		return
+	}
+
+	var order models.Order
+	if err := config.DB.First(&order, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Order not found"})
+		return
+	}
+
+	var expired []models.Payment
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		order.CourierID = nil
+		order.AssignedAt = nil
+		if err := lifecycle.TransitionWithNote(tx, &order, models.OrderStatusCancelled, actor, body.Reason, "courier_id", "assigned_at"); err != nil {
+			return err
+		}
+		// Slot yang dipesan bisa dipakai order lain
+		if err := scheduling.Release(tx, order.PickupSlotID); err != nil {
+			return err
+		}
+		if err := scheduling.Release(tx, order.DeliverySlotID); err != nil {
+			return err
+		}
+		// QR code yang belum dibayar tidak berlaku lagi
+		var err error
+		if expired, err = payment.ExpireCharges(tx, order.ID); err != nil {
+			return err
+		}
+		// Kuota promo dikembalikan, kode promo tetap tercatat di order
+		return tx.Where("order_id = ?", order.ID).Delete(&models.PromoRedemption{}).Error
+	})
+	if err != nil {
+		c.JSON(lifecycle.ErrorStatus(err), gin.H{"message": "Failed to cancel order", "error": err.Error()})
+		return
+	}
+
+	payment.CancelCharges(c.Request.Context(), expired)
+
+	reason := "Order cancelled"
+	if body.Reason != "" {
+		reason += ": " + body.Reason
+	}
+	refunds, err := payment.RefundOrder(c.Request.Context(), config.DB, order.ID, 0, reason, actor.UserID)
+	if err != nil && !errors.Is(err, payment.ErrNothingToRefund) {
+		c.JSON(http.StatusBadGateway, gin.H{
+			"message": "Order cancelled but the refund failed, retry it from the refunds endpoint",
+			"error":   err.Error(),
+			"refunds": refunds,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Order cancelled successfully", "refunds": refunds})
+}
+
+// GetOrderHistory returns the status timeline of an order. Access is limited
+// to the order's customer, its assigned courier and admins in the routes.
+func GetOrderHistory(c *gin.Context) {
+	orderID := c.Param("id")
+
+	var order models.Order
+	if err := config.DB.First(&order, orderID).Error; err != nil {
+		c.JSON(http.StatusNotFound, response.DefaultResponse{
+			Success: false,
+			Message: "Order not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	var events []models.OrderStatusEvent
+	if err := config.DB.Preload("Actor").Preload("Proofs").Where("order_id = ?", order.ID).Order("created_at, id").Find(&events).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, response.DefaultResponse{
+			Success: false,
+			Message: "Failed to retrieve order history",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	eventResponses := []response.OrderStatusEventResponse{}
+	for _, event := range events {
+		eventResponses = append(eventResponses, response.OrderStatusEventResponse{
+			ID:         event.ID,
+			FromStatus: string(event.FromStatus),
+			ToStatus:   string(event.ToStatus),
+			ActorRole:  event.ActorRole,
+			Note:       event.Note,
+			CreatedAt:  event.CreatedAt.Format("2006-01-02 15:04:05"),
+			Actor: response.UserResponse{
+				ID:       event.Actor.ID,
+				Username: event.Actor.Username,
+				Email:    event.Actor.Email,
+			},
+			Proofs: response.NewOrderProofs(event.Proofs),
+		})
+	}
+
+	c.JSON(http.StatusOK, response.DefaultResponse{
+		Success: true,
+		Message: "Successfully retrieved order history",
+		Code:    http.StatusOK,
+		Data:    eventResponses,
+	})
+}
+
+// GetOrderProofFile downloads a pickup or delivery proof of an order. Access
+// is limited to the order's customer, its assigned courier and admins in the
+// routes.
+func GetOrderProofFile(c *gin.Context) {
+	var proof models.OrderProof
+	if err := config.DB.Where("order_id = ?", c.Param("id")).First(&proof, c.Param("proof_id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Proof not found"})
+		return
+	}
+
+	files := storage.Default()
+	if files == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"message": "File storage is not configured"})
+		return
+	}
+	file, err := files.Open(proof.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Proof file is missing"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to read proof file"})
+		return
+	}
+	defer file.Close()
+
+	c.DataFromReader(http.StatusOK, proof.Size, proof.ContentType, file, nil)
+}
@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raihansyahrin/backend_laundry_app.git/config"
+	"github.com/raihansyahrin/backend_laundry_app.git/lifecycle"
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"github.com/raihansyahrin/backend_laundry_app.git/payment"
+)
+
+// PaymentWebhook receives payment notifications from a provider. Providers
+// retry until they get a 2xx answer, so replays are acknowledged without
+// changing anything.
+func PaymentWebhook(c *gin.Context) {
+	provider, ok := payment.Get(c.Param("provider"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Unknown payment provider"})
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid request body"})
+		return
+	}
+
+	notification, err := provider.VerifyWebhook(c.Request.Header, body)
+	if err != nil {
+		if errors.Is(err, payment.ErrInvalidSignature) {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "Invalid signature"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid notification", "error": err.Error()})
+		return
+	}
+
+	var record models.Payment
+	if err := config.DB.Where("provider = ? AND provider_reference = ?", provider.Name(), notification.Reference).First(&record).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Payment not found"})
+		return
+	}
+
+	changed, err := payment.Settle(config.DB, &record, notification.Status, notification.Amount, notification.Raw)
+	if err != nil {
+		if errors.Is(err, payment.ErrAmountMismatch) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"message": "Failed to process notification", "error": err.Error()})
+			return
+		}
+		c.JSON(lifecycle.ErrorStatus(err), gin.H{"message": "Failed to process notification", "error": err.Error()})
+		return
+	}
+
+	if !changed {
+		c.JSON(http.StatusOK, gin.H{"message": "Notification already processed", "status": record.Status})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Notification processed", "status": record.Status})
+}
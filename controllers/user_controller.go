@@ -132,7 +132,18 @@ func UpdateUser(c *gin.Context) {
 		}
 		user.Password = string(hash)
 	}
-	user.Role = body.Role
+	if body.Role != "" && body.Role != user.Role {
+		if !models.IsValidRole(body.Role) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid role"})
+			return
+		}
+		// Hanya admin yang boleh mengubah role user
+		if role, _ := c.Get("role"); role != models.RoleAdmin {
+			c.JSON(http.StatusForbidden, gin.H{"message": "Only admins can change a user's role"})
+			return
+		}
+		user.Role = body.Role
+	}
 
 	if err := config.DB.Save(&user).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to update user"})
@@ -0,0 +1,199 @@
+package dispatch
+
+import (
+	"context"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"gorm.io/gorm"
+)
+
+// Strategies for assigning new orders to couriers
+const (
+	StrategyManual      = "manual"
+	StrategyRoundRobin  = "round_robin"
+	StrategyLeastActive = "least_active"
+	StrategySameArea    = "same_area"
+)
+
+// DefaultAcceptTimeout is how long an assigned courier has to accept an
+// order before it goes back to the job board
+const DefaultAcceptTimeout = 10 * time.Minute
+
+// activeStatuses are the statuses of orders a courier is still working on
+var activeStatuses = []models.OrderStatus{
+	models.OrderStatusWaitingForCourier,
+	models.OrderStatusCourierOnTheWay,
+	models.OrderStatusArrived,
+	models.OrderStatusWaitingForPayment,
+	models.OrderStatusDone,
+	models.OrderStatusDelivering,
+}
+
+// Strategy returns the strategy set in DISPATCH_STRATEGY. Without it new
+// orders wait on the job board.
+func Strategy() string {
+	switch strategy := strings.ToLower(os.Getenv("DISPATCH_STRATEGY")); strategy {
+	case StrategyRoundRobin, StrategyLeastActive, StrategySameArea:
+		return strategy
+	case "", StrategyManual:
+		return StrategyManual
+	default:
+		log.Printf("dispatch: unknown DISPATCH_STRATEGY %q, assigning manually", strategy)
+		return StrategyManual
+	}
+}
+
+// AcceptTimeout returns DISPATCH_ACCEPT_TIMEOUT, a Go duration such as "10m"
+func AcceptTimeout() time.Duration {
+	value := os.Getenv("DISPATCH_ACCEPT_TIMEOUT")
+	if value == "" {
+		return DefaultAcceptTimeout
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		log.Printf("dispatch: invalid DISPATCH_ACCEPT_TIMEOUT %q, using %s", value, DefaultAcceptTimeout)
+		return DefaultAcceptTimeout
+	}
+	return timeout
+}
+
+// candidate is a courier serving the address of the order being assigned
+type candidate struct {
+	CourierID    uint
+	Specificity  int // 1 area, 2 district, 3 sub district
+	ActiveJobs   int64
+	LastAssigned time.Time
+}
+
+// AutoAssign offers a new order to a courier chosen with strategy. The order
+// keeps waiting for courier approval: the courier still accepts it, and if
+// they do not within the accept timeout it returns to the job board. It
+// returns the chosen courier, or nil when the order stays unassigned.
+func AutoAssign(db *gorm.DB, order *models.Order, address models.Address, strategy string) (*uint, error) {
+	if strategy == StrategyManual {
+		return nil, nil
+	}
+
+	// Hanya kurir dengan area yang cocok yang ditawari, kurir tanpa area
+	// tetap bisa mengambil order dari job board
+	var areas []models.CourierArea
+	err := db.Joins("JOIN users ON users.id = courier_areas.courier_id AND users.role = ? AND users.deleted_at IS NULL", models.RoleCourier).
+		Where("courier_areas.area = ?", address.Area).Find(&areas).Error
+	if err != nil {
+		return nil, err
+	}
+
+	byCourier := map[uint]*candidate{}
+	for i := range areas {
+		if !areas[i].Matches(address) {
+			continue
+		}
+		specificity := 1
+		if areas[i].SubDistrict != "" {
+			specificity = 3
+		} else if areas[i].District != "" {
+			specificity = 2
+		}
+		existing := byCourier[areas[i].CourierID]
+		if existing == nil {
+			existing = &candidate{CourierID: areas[i].CourierID}
+			byCourier[areas[i].CourierID] = existing
+		}
+		if specificity > existing.Specificity {
+			existing.Specificity = specificity
+		}
+	}
+	if len(byCourier) == 0 {
+		return nil, nil
+	}
+
+	candidates := make([]*candidate, 0, len(byCourier))
+	for _, entry := range byCourier {
+		if err := db.Model(&models.Order{}).
+			Where("courier_id = ? AND status IN ?", entry.CourierID, activeStatuses).
+			Count(&entry.ActiveJobs).Error; err != nil {
+			return nil, err
+		}
+		var last models.Order
+		if err := db.Select("assigned_at").Where("courier_id = ? AND assigned_at IS NOT NULL", entry.CourierID).
+			Order("assigned_at desc").Limit(1).Find(&last).Error; err != nil {
+			return nil, err
+		}
+		if last.AssignedAt != nil {
+			entry.LastAssigned = *last.AssignedAt
+		}
+		candidates = append(candidates, entry)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		switch strategy {
+		case StrategyLeastActive:
+			if a.ActiveJobs != b.ActiveJobs {
+				return a.ActiveJobs < b.ActiveJobs
+			}
+		case StrategySameArea:
+			if a.Specificity != b.Specificity {
+				return a.Specificity > b.Specificity
+			}
+			if a.ActiveJobs != b.ActiveJobs {
+				return a.ActiveJobs < b.ActiveJobs
+			}
+		}
+		// Round robin: kurir yang paling lama tidak mendapat order lebih dulu
+		if !a.LastAssigned.Equal(b.LastAssigned) {
+			return a.LastAssigned.Before(b.LastAssigned)
+		}
+		return a.CourierID < b.CourierID
+	})
+
+	chosen := candidates[0].CourierID
+	now := time.Now()
+	result := db.Model(&models.Order{}).
+		Where("id = ? AND status = ? AND courier_id IS NULL", order.ID, models.OrderStatusWaitingForCourier).
+		Updates(map[string]interface{}{"courier_id": chosen, "assigned_at": now})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	order.CourierID = &chosen
+	order.AssignedAt = &now
+	return &chosen, nil
+}
+
+// ReleaseExpired returns orders whose assigned courier did not accept them
+// within timeout to the job board
+func ReleaseExpired(db *gorm.DB, timeout time.Duration) (int64, error) {
+	result := db.Model(&models.Order{}).
+		Where("status = ? AND courier_id IS NOT NULL AND assigned_at < ?", models.OrderStatusWaitingForCourier, time.Now().Add(-timeout)).
+		Updates(map[string]interface{}{"courier_id": nil, "assigned_at": nil})
+	return result.RowsAffected, result.Error
+}
+
+// StartReleaser periodically releases expired assignments until ctx is done
+func StartReleaser(ctx context.Context, db *gorm.DB, timeout time.Duration) {
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				released, err := ReleaseExpired(db, timeout)
+				if err != nil {
+					log.Printf("dispatch: failed to release expired assignments: %v", err)
+				} else if released > 0 {
+					log.Printf("dispatch: %d order(s) returned to the job board", released)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,100 @@
+package dispatch
+
+import (
+	"errors"
+
+	"github.com/raihansyahrin/backend_laundry_app.git/lifecycle"
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrAlreadyClaimed is returned when another courier claimed the order first
+	ErrAlreadyClaimed = errors.New("order has already been accepted by another courier")
+	// ErrOutsideArea is returned when the order's address is not served by the courier
+	ErrOutsideArea = errors.New("order is outside the courier's service areas")
+)
+
+// CourierAreas returns the areas assigned to a courier
+func CourierAreas(db *gorm.DB, courierID uint) ([]models.CourierArea, error) {
+	var areas []models.CourierArea
+	err := db.Where("courier_id = ?", courierID).Order("area, district, sub_district").Find(&areas).Error
+	return areas, err
+}
+
+// Serves reports whether any of areas covers address. A courier without
+// areas has not been restricted yet and serves every address.
+func Serves(areas []models.CourierArea, address models.Address) bool {
+	if len(areas) == 0 {
+		return true
+	}
+	for i := range areas {
+		if areas[i].Matches(address) {
+			return true
+		}
+	}
+	return false
+}
+
+// OpenJobs lists orders waiting for a courier whose address lies in one of
+// the courier's areas, oldest first. A courier without areas sees every
+// order. Orders the dispatcher offered to another courier are left out until
+// their offer expires.
+func OpenJobs(db *gorm.DB, courierID uint) ([]models.Order, error) {
+	areas, err := CourierAreas(db, courierID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Kondisi area digabung dengan OR, district dan sub district hanya jika diisi
+	inArea := db.Where("1 = 0")
+	for _, area := range areas {
+		condition := db.Where("addresses.area = ?", area.Area)
+		if area.District != "" {
+			condition = condition.Where("addresses.district = ?", area.District)
+		}
+		if area.SubDistrict != "" {
+			condition = condition.Where("addresses.sub_district = ?", area.SubDistrict)
+		}
+		inArea = inArea.Or(condition)
+	}
+
+	query := db.Joins("JOIN addresses ON addresses.id = orders.address_id").
+		Preload("Address").Preload("Items").Preload("PickupSlot").
+		Where("orders.status = ? AND (orders.courier_id IS NULL OR orders.courier_id = ?)", models.OrderStatusWaitingForCourier, courierID)
+	if len(areas) > 0 {
+		query = query.Where(inArea)
+	}
+
+	var orders []models.Order
+	err = query.Order("orders.created_at, orders.id").Find(&orders).Error
+	return orders, err
+}
+
+// Claim assigns order to a courier and moves it to "Kurir On The Way". The
+// status change is a conditional update, so when two couriers claim the same
+// order at the same moment only one of them wins; the other gets
+// ErrAlreadyClaimed.
+func Claim(db *gorm.DB, order *models.Order, courierID uint, actor lifecycle.Actor) error {
+	if order.CourierID != nil && *order.CourierID != courierID {
+		return ErrAlreadyClaimed
+	}
+
+	previousCourierID, previousAdminID := order.CourierID, order.AdminID
+	order.CourierID = &courierID
+	order.AdminID = nil // Tidak ada admin yang menerima order
+	// Tawaran ke kurir lain yang dibuat setelah order dimuat tidak boleh ditimpa
+	err := lifecycle.TransitionIf(db, order, models.OrderStatusCourierOnTheWay, actor,
+		"courier_id IS NULL OR courier_id = ?", []interface{}{courierID}, "courier_id", "admin_id")
+	if err != nil {
+		order.CourierID, order.AdminID = previousCourierID, previousAdminID
+		if errors.Is(err, lifecycle.ErrConditionFailed) {
+			return ErrAlreadyClaimed
+		}
+		var transitionErr *lifecycle.TransitionError
+		if errors.As(err, &transitionErr) && transitionErr.Current == models.OrderStatusCourierOnTheWay {
+			return ErrAlreadyClaimed
+		}
+	}
+	return err
+}
@@ -0,0 +1,40 @@
+package dispatch
+
+import (
+	"errors"
+
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrAddressWithoutLocation is returned when service areas are configured
+	// but the address has no coordinates to check them against
+	ErrAddressWithoutLocation = errors.New("address has no latitude and longitude, please pin its location")
+	// ErrOutsideServiceArea is returned for addresses no outlet serves
+	ErrOutsideServiceArea = errors.New("address is outside our service area")
+)
+
+// CheckServiceArea returns nil when an active service area covers address.
+// Without any active service area every address is accepted, as before
+// service areas existed.
+func CheckServiceArea(db *gorm.DB, address models.Address) error {
+	var areas []models.ServiceArea
+	if err := db.Where("active = ?", true).Find(&areas).Error; err != nil {
+		return err
+	}
+	if len(areas) == 0 {
+		return nil
+	}
+
+	point, ok := address.Point()
+	if !ok {
+		return ErrAddressWithoutLocation
+	}
+	for i := range areas {
+		if areas[i].Contains(point) {
+			return nil
+		}
+	}
+	return ErrOutsideServiceArea
+}
@@ -0,0 +1,180 @@
+package garments
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrOrderClosed is returned for orders whose garments can no longer change
+	ErrOrderClosed = errors.New("garments of this order can no longer be changed")
+	// ErrDuplicateTag is returned when a tag is used twice in one order
+	ErrDuplicateTag = errors.New("tag is already used in this order")
+	// ErrUnknownTag is returned for a tag that belongs to no order
+	ErrUnknownTag = errors.New("unknown tag")
+	// ErrNotReadyForDelivery is returned for delivery scans before the
+	// laundry is done
+	ErrNotReadyForDelivery = errors.New("garments can only be scanned for delivery once the order is done")
+)
+
+// WrongOrderError is returned when a scanned tag belongs to another order,
+// which means garments of two orders were swapped
+type WrongOrderError struct {
+	Tag     string
+	OrderID uint
+}
+
+func (e *WrongOrderError) Error() string {
+	return fmt.Sprintf("tag %s belongs to order %d", e.Tag, e.OrderID)
+}
+
+// IsGarmentError reports whether err is caused by the request rather than
+// by the database
+func IsGarmentError(err error) bool {
+	var wrongOrder *WrongOrderError
+	return errors.Is(err, ErrOrderClosed) || errors.Is(err, ErrDuplicateTag) ||
+		errors.Is(err, ErrUnknownTag) || errors.Is(err, ErrNotReadyForDelivery) || errors.As(err, &wrongOrder)
+}
+
+// intakeStatuses are the statuses in which garments can be registered: from
+// pickup until the outlet starts working on them
+var intakeStatuses = []models.OrderStatus{
+	models.OrderStatusCourierOnTheWay,
+	models.OrderStatusArrived,
+	models.OrderStatusWaitingForPayment,
+	models.OrderStatusInProgress,
+}
+
+// deliveryStatuses are the statuses in which garments leave the outlet
+var deliveryStatuses = []models.OrderStatus{
+	models.OrderStatusDone,
+	models.OrderStatusDelivering,
+	models.OrderStatusDelivered,
+}
+
+// closedStatuses are the statuses in which tags are no longer scanned
+var closedStatuses = []models.OrderStatus{
+	"",
+	models.OrderStatusWaitingForCourier,
+	models.OrderStatusCompleted,
+	models.OrderStatusCancelled,
+}
+
+func hasStatus(statuses []models.OrderStatus, status models.OrderStatus) bool {
+	for _, candidate := range statuses {
+		if candidate == status {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeStage turns a stage name such as "Quality Check" into
+// "quality_check"
+func NormalizeStage(stage string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(stage)), " ", "_")
+}
+
+// activeGarment returns the most recent garment with tag on an order that is
+// not closed, or an empty garment when there is none
+func activeGarment(db *gorm.DB, tag string) (models.Garment, error) {
+	var garment models.Garment
+	err := db.Joins("JOIN orders ON orders.id = garments.order_id AND orders.status NOT IN ?", closedStatuses).
+		Where("garments.tag = ?", tag).Order("garments.id desc").Limit(1).Find(&garment).Error
+	return garment, err
+}
+
+// Register adds tagged garments to order and records their intake scan. A
+// tag still in use on another open order is reported as a WrongOrderError.
+func Register(db *gorm.DB, order models.Order, garments []models.Garment, actorID uint) error {
+	if !hasStatus(intakeStatuses, order.Status) {
+		return ErrOrderClosed
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		seen := map[string]bool{}
+		now := time.Now()
+		for i := range garments {
+			garment := &garments[i]
+			garment.Tag = strings.TrimSpace(garment.Tag)
+			if seen[garment.Tag] {
+				return fmt.Errorf("%w: %s", ErrDuplicateTag, garment.Tag)
+			}
+			seen[garment.Tag] = true
+
+			var existing int64
+			if err := tx.Model(&models.Garment{}).Where("order_id = ? AND tag = ?", order.ID, garment.Tag).Count(&existing).Error; err != nil {
+				return err
+			}
+			if existing > 0 {
+				return fmt.Errorf("%w: %s", ErrDuplicateTag, garment.Tag)
+			}
+			// Tag fisik yang masih menempel di cucian order lain tidak boleh dipakai ulang
+			other, err := activeGarment(tx, garment.Tag)
+			if err != nil {
+				return err
+			}
+			if other.ID != 0 && other.OrderID != order.ID {
+				return &WrongOrderError{Tag: garment.Tag, OrderID: other.OrderID}
+			}
+
+			garment.ID = 0
+			garment.OrderID = order.ID
+			garment.RegisteredByID = actorID
+			garment.LastStage = models.GarmentStageIntake
+			garment.LastScannedAt = &now
+			garment.Scans = []models.GarmentScan{{OrderID: order.ID, Stage: models.GarmentStageIntake, ScannedByID: actorID}}
+			if err := tx.Create(garment).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Scan records tag being scanned at stage for order. A tag registered on
+// another open order is reported as a WrongOrderError.
+func Scan(db *gorm.DB, order models.Order, tag, stage string, actorID uint) (*models.Garment, error) {
+	if hasStatus(closedStatuses, order.Status) {
+		return nil, ErrOrderClosed
+	}
+	if stage == models.GarmentStageDelivery && !hasStatus(deliveryStatuses, order.Status) {
+		return nil, ErrNotReadyForDelivery
+	}
+	tag = strings.TrimSpace(tag)
+
+	var garment models.Garment
+	err := db.Where("order_id = ? AND tag = ?", order.ID, tag).First(&garment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		other, err := activeGarment(db, tag)
+		if err != nil {
+			return nil, err
+		}
+		if other.ID != 0 {
+			return nil, &WrongOrderError{Tag: tag, OrderID: other.OrderID}
+		}
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTag, tag)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	err = db.Transaction(func(tx *gorm.DB) error {
+		scan := models.GarmentScan{GarmentID: garment.ID, OrderID: order.ID, Stage: stage, ScannedByID: actorID}
+		if err := tx.Create(&scan).Error; err != nil {
+			return err
+		}
+		garment.LastStage, garment.LastScannedAt = stage, &now
+		return tx.Model(&garment).Select("last_stage", "last_scanned_at").Updates(&garment).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &garment, nil
+}
@@ -0,0 +1,75 @@
+package garments
+
+import (
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"gorm.io/gorm"
+)
+
+// Report compares the garments taken in for an order with the ones scanned
+// for delivery
+type Report struct {
+	OrderID        uint
+	Status         models.OrderStatus
+	Quantity       int // jumlah yang dicatat kurir saat penjemputan
+	IntakeCount    int
+	DeliveredCount int
+	Mismatch       bool
+	Missing        []models.Garment
+}
+
+// OrderReport builds the report of one order. Missing lists the garments
+// not scanned for delivery yet.
+func OrderReport(db *gorm.DB, order models.Order) (Report, error) {
+	report := Report{OrderID: order.ID, Status: order.Status, Quantity: order.Quantity, Missing: []models.Garment{}}
+
+	var garments []models.Garment
+	if err := db.Where("order_id = ?", order.ID).Order("id").Find(&garments).Error; err != nil {
+		return report, err
+	}
+	var delivered []uint
+	if err := db.Model(&models.GarmentScan{}).Distinct("garment_id").
+		Where("order_id = ? AND stage = ?", order.ID, models.GarmentStageDelivery).
+		Pluck("garment_id", &delivered).Error; err != nil {
+		return report, err
+	}
+
+	isDelivered := map[uint]bool{}
+	for _, id := range delivered {
+		isDelivered[id] = true
+	}
+	report.IntakeCount = len(garments)
+	for _, garment := range garments {
+		if isDelivered[garment.ID] {
+			report.DeliveredCount++
+		} else {
+			report.Missing = append(report.Missing, garment)
+		}
+	}
+	report.Mismatch = report.DeliveredCount != report.IntakeCount
+	return report, nil
+}
+
+// MismatchSummary is an order handed back with a different number of
+// garments than it was taken in with
+type MismatchSummary struct {
+	OrderID        uint   `json:"order_id"`
+	Status         string `json:"status"`
+	IntakeCount    int    `json:"intake_count"`
+	DeliveredCount int    `json:"delivered_count"`
+}
+
+// Mismatches lists the delivered and completed orders whose delivered count
+// differs from their intake count
+func Mismatches(db *gorm.DB) ([]MismatchSummary, error) {
+	summaries := []MismatchSummary{}
+	err := db.Table("garments").
+		Select("garments.order_id, orders.status, COUNT(DISTINCT garments.id) AS intake_count, COUNT(DISTINCT garment_scans.garment_id) AS delivered_count").
+		Joins("JOIN orders ON orders.id = garments.order_id").
+		Joins("LEFT JOIN garment_scans ON garment_scans.garment_id = garments.id AND garment_scans.stage = ? AND garment_scans.deleted_at IS NULL", models.GarmentStageDelivery).
+		Where("garments.deleted_at IS NULL AND orders.status IN ?", []models.OrderStatus{models.OrderStatusDelivered, models.OrderStatusCompleted}).
+		Group("garments.order_id, orders.status").
+		Having("COUNT(DISTINCT garments.id) <> COUNT(DISTINCT garment_scans.garment_id)").
+		Order("garments.order_id").
+		Scan(&summaries).Error
+	return summaries, err
+}
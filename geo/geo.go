@@ -0,0 +1,70 @@
+package geo
+
+import (
+	"math"
+)
+
+// EarthRadiusKm is the mean radius of the earth
+const EarthRadiusKm = 6371.0
+
+// Point is a WGS84 coordinate
+type Point struct {
+	Lat float64 `json:"latitude"`
+	Lng float64 `json:"longitude"`
+}
+
+// Valid reports whether the point lies within the WGS84 range
+func (p Point) Valid() bool {
+	return p.Lat >= -90 && p.Lat <= 90 && p.Lng >= -180 && p.Lng <= 180
+}
+
+// DistanceKm is the great-circle distance between a and b
+func DistanceKm(a, b Point) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(b.Lat - a.Lat)
+	dLng := toRad(b.Lng - a.Lng)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(a.Lat))*math.Cos(toRad(b.Lat))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * EarthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// InRadius reports whether p lies within radiusKm of center
+func InRadius(p, center Point, radiusKm float64) bool {
+	return DistanceKm(p, center) <= radiusKm
+}
+
+// edgeTolerance is how far, in degrees, a point may be off an edge and still
+// count as lying on it; about 0.1 mm
+const edgeTolerance = 1e-9
+
+// InPolygon reports whether p lies inside polygon, using ray casting. The
+// polygon is closed implicitly; it needs at least three vertices. Points on
+// an edge or vertex count as inside, so an address on the border of two
+// areas matches both.
+func InPolygon(p Point, polygon []Point) bool {
+	if len(polygon) < 3 {
+		return false
+	}
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		a, b := polygon[i], polygon[j]
+		if onSegment(p, a, b) {
+			return true
+		}
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) &&
+			p.Lng < (b.Lng-a.Lng)*(p.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lng {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// onSegment reports whether p lies on the line segment from a to b
+func onSegment(p, a, b Point) bool {
+	if p.Lat < math.Min(a.Lat, b.Lat)-edgeTolerance || p.Lat > math.Max(a.Lat, b.Lat)+edgeTolerance ||
+		p.Lng < math.Min(a.Lng, b.Lng)-edgeTolerance || p.Lng > math.Max(a.Lng, b.Lng)+edgeTolerance {
+		return false
+	}
+	cross := (b.Lat-a.Lat)*(p.Lng-a.Lng) - (b.Lng-a.Lng)*(p.Lat-a.Lat)
+	return math.Abs(cross) <= edgeTolerance*math.Hypot(b.Lat-a.Lat, b.Lng-a.Lng)
+}
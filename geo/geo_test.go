@@ -0,0 +1,101 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistanceKm(t *testing.T) {
+	monas := Point{Lat: -6.175392, Lng: 106.827153}
+	bundaranHI := Point{Lat: -6.194917, Lng: 106.823031}
+
+	tests := []struct {
+		name string
+		a, b Point
+		want float64
+	}{
+		{"same point", monas, monas, 0},
+		{"monas to bundaran HI", monas, bundaranHI, 2.22},
+		{"one degree of latitude", Point{Lat: 0, Lng: 0}, Point{Lat: 1, Lng: 0}, 111.19},
+		{"one degree of longitude at the equator", Point{Lat: 0, Lng: 0}, Point{Lat: 0, Lng: 1}, 111.19},
+		{"across the antimeridian", Point{Lat: 0, Lng: 179.5}, Point{Lat: 0, Lng: -179.5}, 111.19},
+		{"antipodes", Point{Lat: 0, Lng: 0}, Point{Lat: 0, Lng: 180}, math.Pi * EarthRadiusKm},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DistanceKm(tt.a, tt.b)
+			if math.Abs(got-tt.want) > 0.01 {
+				t.Errorf("DistanceKm() = %.3f, want %.2f", got, tt.want)
+			}
+			if back := DistanceKm(tt.b, tt.a); math.Abs(back-got) > 1e-9 {
+				t.Errorf("DistanceKm() is not symmetric: %v and %v", got, back)
+			}
+		})
+	}
+}
+
+func TestInRadius(t *testing.T) {
+	center := Point{Lat: 0, Lng: 0}
+	edge := Point{Lat: 1, Lng: 0}
+	distance := DistanceKm(center, edge)
+
+	tests := []struct {
+		name   string
+		p      Point
+		radius float64
+		want   bool
+	}{
+		{"center", center, 0, true},
+		{"inside", edge, distance + 1, true},
+		{"on the boundary", edge, distance, true},
+		{"outside", edge, distance - 0.001, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := InRadius(tt.p, center, tt.radius); got != tt.want {
+				t.Errorf("InRadius() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInPolygon(t *testing.T) {
+	square := []Point{{0, 0}, {0, 2}, {2, 2}, {2, 0}}
+	// an L shape with the notch at the top right
+	concave := []Point{{0, 0}, {0, 4}, {2, 4}, {2, 2}, {4, 2}, {4, 0}}
+
+	tests := []struct {
+		name    string
+		p       Point
+		polygon []Point
+		want    bool
+	}{
+		{"inside", Point{1, 1}, square, true},
+		{"outside", Point{3, 1}, square, false},
+		{"outside in line with an edge", Point{1, 3}, square, false},
+		{"on the bottom edge", Point{0, 1}, square, true},
+		{"on the top edge", Point{2, 1}, square, true},
+		{"on the left edge", Point{1, 0}, square, true},
+		{"on the right edge", Point{1, 2}, square, true},
+		{"on a vertex", Point{2, 2}, square, true},
+		{"on the first vertex", Point{0, 0}, square, true},
+		{"just outside an edge", Point{2.000001, 1}, square, false},
+		{"concave inside", Point{1, 3}, concave, true},
+		{"concave notch", Point{3, 3}, concave, false},
+		{"concave inner corner", Point{2, 2}, concave, true},
+		{"concave inner edge", Point{3, 2}, concave, true},
+		{"diagonal edge", Point{1, 1}, []Point{{0, 0}, {2, 2}, {2, 0}}, true},
+		{"two vertices", Point{0, 0}, []Point{{0, 0}, {1, 1}}, false},
+		{"no vertices", Point{0, 0}, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := InPolygon(tt.p, tt.polygon); got != tt.want {
+				t.Errorf("InPolygon(%v) = %v, want %v", tt.p, got, tt.want)
+			}
+		})
+	}
+}
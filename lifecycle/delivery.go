@@ -0,0 +1,88 @@
+package lifecycle
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"gorm.io/gorm"
+)
+
+// MaxDeliveryCodeAttempts is the number of wrong codes a courier may submit
+// before only an admin can confirm the delivery
+const MaxDeliveryCodeAttempts = 5
+
+var (
+	// ErrInvalidDeliveryCode is returned for a code that does not match
+	ErrInvalidDeliveryCode = errors.New("invalid delivery code")
+	// ErrDeliveryCodeLocked is returned once too many wrong codes were given
+	ErrDeliveryCodeLocked = errors.New("too many wrong delivery codes, ask an admin to confirm the delivery")
+	// ErrNoDeliveryCode is returned for orders sent out without a code
+	ErrNoDeliveryCode = errors.New("order has no delivery code, ask an admin to confirm the delivery")
+)
+
+// NewDeliveryCode returns a random six digit code
+func NewDeliveryCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// ConfirmDelivery moves an order that is out for delivery to delivered when
+// code matches the one shown to the customer. Every attempt is counted before
+// the code is compared, with a conditional update, so parallel guesses cannot
+// exceed MaxDeliveryCodeAttempts.
+func ConfirmDelivery(db *gorm.DB, order *models.Order, code string, actor Actor) error {
+	if err := Check(order.Status, models.OrderStatusDelivered, actor.Role); err != nil {
+		return err
+	}
+	if order.DeliveryCode == "" {
+		return ErrNoDeliveryCode
+	}
+
+	result := db.Model(&models.Order{}).
+		Where("id = ? AND delivery_code_attempts < ?", order.ID, MaxDeliveryCodeAttempts).
+		UpdateColumn("delivery_code_attempts", gorm.Expr("delivery_code_attempts + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		order.DeliveryCodeAttempts = MaxDeliveryCodeAttempts
+		return ErrDeliveryCodeLocked
+	}
+	if err := db.Model(&models.Order{}).Select("delivery_code_attempts").Where("id = ?", order.ID).Scan(&order.DeliveryCodeAttempts).Error; err != nil {
+		return err
+	}
+
+	code = strings.TrimSpace(code)
+	if subtle.ConstantTimeCompare([]byte(code), []byte(order.DeliveryCode)) != 1 {
+		return ErrInvalidDeliveryCode
+	}
+
+	return deliver(db, order, actor, "Delivery confirmed with customer code")
+}
+
+// OverrideDelivery lets an admin mark an order delivered without the
+// customer's code, e.g. when the customer cannot be reached. The reason is
+// kept in the order history.
+func OverrideDelivery(db *gorm.DB, order *models.Order, reason string, actor Actor) error {
+	return deliver(db, order, actor, "Delivery confirmed by admin: "+reason)
+}
+
+// deliver clears the code, which is no longer needed, together with the
+// status change
+func deliver(db *gorm.DB, order *models.Order, actor Actor, note string) error {
+	code := order.DeliveryCode
+	order.DeliveryCode = ""
+	if err := TransitionWithNote(db, order, models.OrderStatusDelivered, actor, note, "delivery_code"); err != nil {
+		order.DeliveryCode = code
+		return err
+	}
+	return nil
+}
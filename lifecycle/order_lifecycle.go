@@ -0,0 +1,195 @@
+package lifecycle
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Actor is the user moving an order from one status to another.
+type Actor struct {
+	UserID uint
+	Role   string
+}
+
+// System is the actor for changes made by the app itself.
+var System = Actor{Role: models.RoleSystem}
+
+// ActorFromContext builds an Actor from the values set by AuthMiddleware.
+func ActorFromContext(c *gin.Context) Actor {
+	var actor Actor
+	if userID, ok := c.Get("user_id"); ok {
+		actor.UserID, _ = userID.(uint)
+	}
+	if role, ok := c.Get("role"); ok {
+		actor.Role, _ = role.(string)
+	}
+	return actor
+}
+
+// transitions declares, for every current status, which statuses an order may
+// move to and which roles are allowed to make that move. The empty status is
+// the state of an order that has not been created yet.
+var transitions = map[models.OrderStatus]map[models.OrderStatus][]string{
+	"": {
+		models.OrderStatusWaitingForCourier: {models.RoleCustomer},
+	},
+	models.OrderStatusWaitingForCourier: {
+		models.OrderStatusCourierOnTheWay: {models.RoleCourier},
+		models.OrderStatusCancelled:       {models.RoleCustomer, models.RoleAdmin},
+	},
+	models.OrderStatusCourierOnTheWay: {
+		models.OrderStatusArrived:   {models.RoleCourier},
+		models.OrderStatusCancelled: {models.RoleAdmin},
+	},
+	models.OrderStatusArrived: {
+		models.OrderStatusInProgress:        {models.RoleCourier, models.RoleSystem},
+		models.OrderStatusWaitingForPayment: {models.RoleCustomer, models.RoleCourier},
+		models.OrderStatusCancelled:         {models.RoleAdmin},
+	},
+	models.OrderStatusWaitingForPayment: {
+		models.OrderStatusInProgress: {models.RoleAdmin, models.RoleSystem},
+		models.OrderStatusArrived:    {models.RoleSystem},
+		models.OrderStatusCancelled:  {models.RoleAdmin},
+	},
+	models.OrderStatusInProgress: {
+		models.OrderStatusDone:      {models.RoleAdmin},
+		models.OrderStatusCancelled: {models.RoleAdmin},
+	},
+	models.OrderStatusDone: {
+		models.OrderStatusDelivering: {models.RoleCourier},
+		models.OrderStatusCancelled:  {models.RoleAdmin},
+	},
+	models.OrderStatusDelivering: {
+		models.OrderStatusDelivered: {models.RoleCourier, models.RoleAdmin},
+		models.OrderStatusCancelled: {models.RoleAdmin},
+	},
+	models.OrderStatusDelivered: {
+		models.OrderStatusCompleted: {models.RoleCustomer, models.RoleCourier},
+	},
+}
+
+// TransitionError is returned when an order cannot move from its current
+// status to the requested one.
+type TransitionError struct {
+	Current   models.OrderStatus
+	Requested models.OrderStatus
+	Role      string
+}
+
+func (e *TransitionError) Error() string {
+	if _, ok := transitions[e.Current][e.Requested]; ok {
+		return fmt.Sprintf("role %q cannot move order from %q to %q", e.Role, e.Current, e.Requested)
+	}
+	return fmt.Sprintf("cannot move order from %q to %q", e.Current, e.Requested)
+}
+
+// Check reports whether role may move an order from one status to another.
+func Check(from, to models.OrderStatus, role string) error {
+	for _, allowed := range transitions[from][to] {
+		if allowed == role {
+			return nil
+		}
+	}
+	return &TransitionError{Current: from, Requested: to, Role: role}
+}
+
+// Transition moves order to the requested status and persists it. New orders
+// are created, existing ones are only updated if their status has not been
+// changed by someone else in the meantime. Besides the status only the given
+// columns are written, taken from order, so fields changed concurrently by
+// others are left alone.
+func Transition(db *gorm.DB, order *models.Order, to models.OrderStatus, actor Actor, columns ...string) error {
+	return TransitionWithNote(db, order, to, actor, "", columns...)
+}
+
+// TransitionWithNote is Transition with a note stored in the order's status
+// history next to the change.
+func TransitionWithNote(db *gorm.DB, order *models.Order, to models.OrderStatus, actor Actor, note string, columns ...string) error {
+	return transition(db, order, to, actor, note, nil, columns)
+}
+
+// ErrConditionFailed is returned by TransitionIf when the order still has its
+// status but no longer matches the condition
+var ErrConditionFailed = errors.New("order was changed by someone else")
+
+// TransitionIf is Transition for an existing order that is only updated when
+// it also matches condition, a SQL expression with args, at that moment.
+func TransitionIf(db *gorm.DB, order *models.Order, to models.OrderStatus, actor Actor, condition string, args []interface{}, columns ...string) error {
+	return transition(db, order, to, actor, "", append([]interface{}{condition}, args...), columns)
+}
+
+func transition(db *gorm.DB, order *models.Order, to models.OrderStatus, actor Actor, note string, condition []interface{}, columns []string) error {
+	from := order.Status
+	if err := Check(from, to, actor.Role); err != nil {
+		return err
+	}
+
+	isNew := order.ID == 0
+	order.Status = to
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if isNew {
+			if err := tx.Omit(clause.Associations).Create(order).Error; err != nil {
+				return err
+			}
+		} else {
+			query := tx.Model(order).Select(append([]string{"status"}, columns...)).Where("status = ?", from)
+			if len(condition) > 0 {
+				query = query.Where(condition[0], condition[1:]...)
+			}
+			result := query.Updates(order)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				var current models.Order
+				if err := tx.Select("status").First(&current, order.ID).Error; err != nil {
+					return err
+				}
+				if current.Status == from && len(condition) > 0 {
+					return ErrConditionFailed
+				}
+				return &TransitionError{Current: current.Status, Requested: to, Role: actor.Role}
+			}
+		}
+
+		event := models.OrderStatusEvent{
+			OrderID:    order.ID,
+			FromStatus: from,
+			ToStatus:   to,
+			ActorRole:  actor.Role,
+			Note:       note,
+		}
+		if actor.UserID != 0 {
+			event.ActorID = &actor.UserID
+		}
+		return tx.Create(&event).Error
+	})
+	if err != nil {
+		order.Status = from
+		if isNew {
+			order.ID = 0
+		}
+		var transitionErr *TransitionError
+		if errors.As(err, &transitionErr) {
+			order.Status = transitionErr.Current
+		}
+		return err
+	}
+	return nil
+}
+
+// ErrorStatus returns the HTTP status code a handler should answer with for an
+// error returned by Transition.
+func ErrorStatus(err error) int {
+	var transitionErr *TransitionError
+	if errors.As(err, &transitionErr) || errors.Is(err, ErrConditionFailed) {
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,117 @@
+package lifecycle
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    models.OrderStatus
+		to      models.OrderStatus
+		role    string
+		allowed bool
+	}{
+		{"customer creates order", "", models.OrderStatusWaitingForCourier, models.RoleCustomer, true},
+		{"courier cannot create order", "", models.OrderStatusWaitingForCourier, models.RoleCourier, false},
+		{"courier claims order", models.OrderStatusWaitingForCourier, models.OrderStatusCourierOnTheWay, models.RoleCourier, true},
+		{"customer cannot claim order", models.OrderStatusWaitingForCourier, models.OrderStatusCourierOnTheWay, models.RoleCustomer, false},
+		{"customer cancels before pickup", models.OrderStatusWaitingForCourier, models.OrderStatusCancelled, models.RoleCustomer, true},
+		{"customer cannot cancel on the way", models.OrderStatusCourierOnTheWay, models.OrderStatusCancelled, models.RoleCustomer, false},
+		{"courier cannot cancel", models.OrderStatusCourierOnTheWay, models.OrderStatusCancelled, models.RoleCourier, false},
+		{"admin cancels on the way", models.OrderStatusCourierOnTheWay, models.OrderStatusCancelled, models.RoleAdmin, true},
+		{"courier arrives", models.OrderStatusCourierOnTheWay, models.OrderStatusArrived, models.RoleCourier, true},
+		{"admin cannot arrive", models.OrderStatusCourierOnTheWay, models.OrderStatusArrived, models.RoleAdmin, false},
+		{"courier takes cash", models.OrderStatusArrived, models.OrderStatusInProgress, models.RoleCourier, true},
+		{"customer cannot skip payment", models.OrderStatusArrived, models.OrderStatusInProgress, models.RoleCustomer, false},
+		{"customer pays by QRIS", models.OrderStatusArrived, models.OrderStatusWaitingForPayment, models.RoleCustomer, true},
+		{"system settles payment", models.OrderStatusWaitingForPayment, models.OrderStatusInProgress, models.RoleSystem, true},
+		{"courier cannot settle payment", models.OrderStatusWaitingForPayment, models.OrderStatusInProgress, models.RoleCourier, false},
+		{"system reopens expired payment", models.OrderStatusWaitingForPayment, models.OrderStatusArrived, models.RoleSystem, true},
+		{"customer cannot reopen payment", models.OrderStatusWaitingForPayment, models.OrderStatusArrived, models.RoleCustomer, false},
+		{"admin marks done", models.OrderStatusInProgress, models.OrderStatusDone, models.RoleAdmin, true},
+		{"courier cannot mark done", models.OrderStatusInProgress, models.OrderStatusDone, models.RoleCourier, false},
+		{"courier delivers", models.OrderStatusDone, models.OrderStatusDelivering, models.RoleCourier, true},
+		{"courier confirms delivery", models.OrderStatusDelivering, models.OrderStatusDelivered, models.RoleCourier, true},
+		{"admin overrides delivery", models.OrderStatusDelivering, models.OrderStatusDelivered, models.RoleAdmin, true},
+		{"customer cannot confirm delivery", models.OrderStatusDelivering, models.OrderStatusDelivered, models.RoleCustomer, false},
+		{"customer completes", models.OrderStatusDelivered, models.OrderStatusCompleted, models.RoleCustomer, true},
+		{"admin cannot complete", models.OrderStatusDelivered, models.OrderStatusCompleted, models.RoleAdmin, false},
+		{"delivered cannot be cancelled", models.OrderStatusDelivered, models.OrderStatusCancelled, models.RoleAdmin, false},
+		{"skipping statuses", models.OrderStatusWaitingForCourier, models.OrderStatusDone, models.RoleAdmin, false},
+		{"going back", models.OrderStatusDone, models.OrderStatusInProgress, models.RoleAdmin, false},
+		{"completed is final", models.OrderStatusCompleted, models.OrderStatusCancelled, models.RoleAdmin, false},
+		{"cancelled is final", models.OrderStatusCancelled, models.OrderStatusWaitingForCourier, models.RoleCustomer, false},
+		{"unknown role", models.OrderStatusInProgress, models.OrderStatusDone, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Check(tt.from, tt.to, tt.role)
+			if tt.allowed && err != nil {
+				t.Fatalf("Check() = %v, want allowed", err)
+			}
+			if tt.allowed {
+				return
+			}
+			var transitionErr *TransitionError
+			if !errors.As(err, &transitionErr) {
+				t.Fatalf("Check() = %v, want a TransitionError", err)
+			}
+			if transitionErr.Current != tt.from || transitionErr.Requested != tt.to {
+				t.Errorf("TransitionError = %q -> %q, want %q -> %q", transitionErr.Current, transitionErr.Requested, tt.from, tt.to)
+			}
+		})
+	}
+}
+
+func TestTransitionErrorMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		err  TransitionError
+		want string
+	}{
+		{
+			name: "move exists for another role",
+			err:  TransitionError{Current: models.OrderStatusInProgress, Requested: models.OrderStatusDone, Role: models.RoleCourier},
+			want: `role "courier" cannot move order from "in progress" to "done"`,
+		},
+		{
+			name: "move does not exist",
+			err:  TransitionError{Current: models.OrderStatusDone, Requested: models.OrderStatusInProgress, Role: models.RoleAdmin},
+			want: `cannot move order from "done" to "in progress"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.want {
+				t.Errorf("Error() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"transition error", &TransitionError{}, http.StatusConflict},
+		{"condition failed", ErrConditionFailed, http.StatusConflict},
+		{"database error", errors.New("connection refused"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ErrorStatus(tt.err); got != tt.want {
+				t.Errorf("ErrorStatus() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,13 +1,19 @@
 package main
 
 import (
+	"context"
 	"log"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 
 	"github.com/raihansyahrin/backend_laundry_app.git/config"
+	"github.com/raihansyahrin/backend_laundry_app.git/dispatch"
+	"github.com/raihansyahrin/backend_laundry_app.git/payment"
+	"github.com/raihansyahrin/backend_laundry_app.git/processing"
 	"github.com/raihansyahrin/backend_laundry_app.git/routes"
+	"github.com/raihansyahrin/backend_laundry_app.git/storage"
+	"github.com/raihansyahrin/backend_laundry_app.git/tracking"
 )
 
 func main() {
@@ -22,6 +28,30 @@ func main() {
 	// Connect to database
 	config.ConnectDatabase()
 
+	// Create the default processing stages on first start
+	if err := processing.EnsureDefaults(config.DB); err != nil {
+		log.Fatal(err)
+	}
+
+	// Register payment providers
+	if err := payment.Setup(); err != nil {
+		log.Fatal(err)
+	}
+
+	// Configure where proof photos are stored
+	if err := storage.Setup(); err != nil {
+		log.Fatal(err)
+	}
+
+	// Poll pending payments, expire stale QR codes and reconcile daily
+	payment.NewWorkerFromEnv(config.DB).Start(context.Background())
+
+	// Return auto-assigned orders nobody accepted to the job board
+	dispatch.StartReleaser(context.Background(), config.DB, dispatch.AcceptTimeout())
+
+	// Drop courier positions older than the retention
+	tracking.StartPurger(context.Background(), config.DB)
+
 	// Setup routes with middleware
 	routes.SetupRoutes(r)
 
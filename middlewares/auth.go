@@ -36,8 +36,11 @@ package middlewares
 import (
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/raihansyahrin/backend_laundry_app.git/config"
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
 	"github.com/raihansyahrin/backend_laundry_app.git/utils"
 )
 
@@ -50,7 +53,12 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		tokenString := strings.Split(authHeader, "Bearer ")[1]
+		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+		if tokenString == authHeader {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "Authorization header must use the Bearer scheme"})
+			c.Abort()
+			return
+		}
 
 		claims, err := utils.ValidateJWT(tokenString)
 		if err != nil {
@@ -59,8 +67,25 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		c.Set("user_id", claims.UserID)
-		c.Set("role", claims.Role)
+		// Token hanya berlaku selama sesinya belum dicabut
+		var session models.Session
+		if err := config.DB.Where("id = ? AND user_id = ? AND revoked_at IS NULL AND expires_at > ?", claims.SessionID, claims.UserID, time.Now()).First(&session).Error; err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "Session has been revoked"})
+			c.Abort()
+			return
+		}
+
+		// User yang sudah dihapus tidak boleh lagi mengakses API
+		var user models.User
+		if err := config.DB.First(&user, claims.UserID).Error; err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "User no longer exists"})
+			c.Abort()
+			return
+		}
+
+		c.Set("user_id", user.ID)
+		c.Set("role", user.Role)
+		c.Set("session_id", session.ID)
 
 		c.Next()
 	}
@@ -0,0 +1,15 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// Deprecated marks the response of an old route as deprecated and points
+// clients to the route that replaces it, built from the current URL path.
+func Deprecated(successor func(c *gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Deprecation", "true")
+		c.Header("Link", "<"+successor(c)+">; rel=\"successor-version\"")
+		c.Next()
+	}
+}
@@ -0,0 +1,201 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raihansyahrin/backend_laundry_app.git/config"
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"gorm.io/gorm"
+)
+
+// Policy decides whether the authenticated user may access the resource the
+// request points at. It must run after AuthMiddleware.
+type Policy func(c *gin.Context) (bool, error)
+
+// IDSource extracts the ID of the resource a request points at.
+type IDSource func(c *gin.Context) (uint, error)
+
+// OwnerResolver returns the ID of the user a resource belongs to, or nil
+// when the resource has no such user (e.g. an order without a courier yet).
+type OwnerResolver func(c *gin.Context) (*uint, error)
+
+var errInvalidID = errors.New("invalid ID")
+
+// Authorize lets the request through when at least one of the policies
+// allows it.
+func Authorize(policies ...Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, policy := range policies {
+			allowed, err := policy(c)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"message": "Resource not found"})
+				c.Abort()
+				return
+			}
+			if errors.Is(err, errInvalidID) {
+				c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+				c.Abort()
+				return
+			}
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to check permissions"})
+				c.Abort()
+				return
+			}
+			if allowed {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"message": "You don't have permission to access this resource"})
+		c.Abort()
+	}
+}
+
+// OwnerOrAdmin allows the user the resource belongs to and admins.
+func OwnerOrAdmin(owner OwnerResolver) gin.HandlerFunc {
+	return Authorize(Roles(models.RoleAdmin), Owner(owner))
+}
+
+// Roles allows users having one of the given roles.
+func Roles(roles ...string) Policy {
+	return func(c *gin.Context) (bool, error) {
+		role, _ := c.Get("role")
+		for _, r := range roles {
+			if role == r {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+}
+
+// Owner allows the user the resource belongs to.
+func Owner(owner OwnerResolver) Policy {
+	return func(c *gin.Context) (bool, error) {
+		userID, _ := c.Get("user_id")
+		ownerID, err := owner(c)
+		if err != nil {
+			return false, err
+		}
+		return ownerID != nil && *ownerID == userID, nil
+	}
+}
+
+// Param reads the resource ID from a route parameter.
+func Param(name string) IDSource {
+	return func(c *gin.Context) (uint, error) {
+		return parseID(name, c.Param(name))
+	}
+}
+
+// BodyField reads the resource ID from a JSON or form field of the request
+// body. The body is left intact for the handler to bind.
+func BodyField(name string) IDSource {
+	return func(c *gin.Context) (uint, error) {
+		if !strings.HasPrefix(c.ContentType(), "application/json") {
+			return parseID(name, c.PostForm(name))
+		}
+
+		raw, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			return 0, err
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(raw))
+
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			return 0, fmt.Errorf("%w: malformed request body", errInvalidID)
+		}
+		var id uint
+		if err := json.Unmarshal(fields[name], &id); err != nil {
+			return 0, fmt.Errorf("%w: %s is required", errInvalidID, name)
+		}
+		return id, nil
+	}
+}
+
+func parseID(name, value string) (uint, error) {
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s must be a number", errInvalidID, name)
+	}
+	return uint(id), nil
+}
+
+// User resolves to the user the ID refers to, for routes about a user itself.
+func User(id IDSource) OwnerResolver {
+	return func(c *gin.Context) (*uint, error) {
+		userID, err := id(c)
+		if err != nil {
+			return nil, err
+		}
+		return &userID, nil
+	}
+}
+
+// AddressOwner resolves to the customer an address belongs to.
+func AddressOwner(id IDSource) OwnerResolver {
+	return func(c *gin.Context) (*uint, error) {
+		addressID, err := id(c)
+		if err != nil {
+			return nil, err
+		}
+		var address models.Address
+		if err := config.DB.Select("customer_id").First(&address, addressID).Error; err != nil {
+			return nil, err
+		}
+		return &address.CustomerID, nil
+	}
+}
+
+// OrderCustomer resolves to the customer who placed an order.
+func OrderCustomer(id IDSource) OwnerResolver {
+	return func(c *gin.Context) (*uint, error) {
+		order, err := findOrder(c, id)
+		if err != nil {
+			return nil, err
+		}
+		return &order.CustomerID, nil
+	}
+}
+
+// OrderCourier resolves to the courier assigned to an order.
+func OrderCourier(id IDSource) OwnerResolver {
+	return func(c *gin.Context) (*uint, error) {
+		order, err := findOrder(c, id)
+		if err != nil {
+			return nil, err
+		}
+		return order.CourierID, nil
+	}
+}
+
+// findOrder loads the order once per request so several policies on the
+// same route do not query it repeatedly.
+func findOrder(c *gin.Context, id IDSource) (*models.Order, error) {
+	orderID, err := id(c)
+	if err != nil {
+		return nil, err
+	}
+	if cached, ok := c.Get("policy_order"); ok {
+		if order := cached.(*models.Order); order.ID == orderID {
+			return order, nil
+		}
+	}
+	var order models.Order
+	if err := config.DB.Select("id", "customer_id", "courier_id").First(&order, orderID).Error; err != nil {
+		return nil, err
+	}
+	c.Set("policy_order", &order)
+	return &order, nil
+}
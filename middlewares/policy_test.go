@@ -0,0 +1,230 @@
+package middlewares
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raihansyahrin/backend_laundry_app.git/config"
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// policyDriver is a tiny database/sql driver answering the lookups made by
+// the policies, so they can be tested without a MySQL server.
+type policyDriver struct{}
+
+type policyConn struct{}
+
+type policyStmt struct{ query string }
+
+type policyRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+// policyOrders maps order IDs to their customer and courier; a zero courier
+// means the order has none yet
+var policyOrders = map[int64][2]int64{
+	10: {1, 5},
+	11: {1, 0},
+}
+
+// policyAddresses maps address IDs to their customer
+var policyAddresses = map[int64]int64{
+	20: 1,
+}
+
+func (policyDriver) Open(string) (driver.Conn, error) { return policyConn{}, nil }
+
+func (policyConn) Prepare(query string) (driver.Stmt, error) { return policyStmt{query: query}, nil }
+func (policyConn) Close() error                              { return nil }
+func (policyConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+func (s policyStmt) Close() error  { return nil }
+func (s policyStmt) NumInput() int { return -1 }
+func (s policyStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+
+func (s policyStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if len(args) == 0 {
+		return nil, errors.New("missing ID")
+	}
+	id, _ := args[0].(int64)
+	switch {
+	case strings.Contains(s.query, "`orders`"):
+		rows := &policyRows{columns: []string{"id", "customer_id", "courier_id"}}
+		if order, ok := policyOrders[id]; ok {
+			var courierID driver.Value
+			if order[1] != 0 {
+				courierID = order[1]
+			}
+			rows.rows = append(rows.rows, []driver.Value{id, order[0], courierID})
+		}
+		return rows, nil
+	case strings.Contains(s.query, "`addresses`"):
+		rows := &policyRows{columns: []string{"customer_id"}}
+		if customerID, ok := policyAddresses[id]; ok {
+			rows.rows = append(rows.rows, []driver.Value{customerID})
+		}
+		return rows, nil
+	}
+	return nil, errors.New("unexpected query: " + s.query)
+}
+
+func (r *policyRows) Columns() []string { return r.columns }
+func (r *policyRows) Close() error      { return nil }
+func (r *policyRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func init() {
+	sql.Register("policytest", policyDriver{})
+}
+
+func setupPolicyDB(t *testing.T) {
+	t.Helper()
+	conn, err := sql.Open("policytest", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: conn, SkipInitializeWithVersion: true}), &gorm.Config{
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := config.DB
+	config.DB = db
+	t.Cleanup(func() { config.DB = previous })
+}
+
+// policyRequest runs a request for path through guard as the given user and
+// returns the response status
+func policyRequest(guard gin.HandlerFunc, route, method, path, body string, userID uint, role string) int {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Handle(method, route, func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Set("role", role)
+		c.Next()
+	}, guard, func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestPolicies(t *testing.T) {
+	setupPolicyDB(t)
+
+	orderCustomer := OwnerOrAdmin(OrderCustomer(Param("id")))
+	orderCourier := Authorize(Owner(OrderCourier(Param("id"))))
+	orderParties := Authorize(
+		Roles(models.RoleAdmin),
+		Owner(OrderCustomer(BodyField("order_id"))),
+		Owner(OrderCourier(BodyField("order_id"))),
+	)
+	addressOwner := OwnerOrAdmin(AddressOwner(Param("id")))
+	self := OwnerOrAdmin(User(Param("id")))
+
+	tests := []struct {
+		name   string
+		guard  gin.HandlerFunc
+		route  string
+		method string
+		path   string
+		body   string
+		userID uint
+		role   string
+		want   int
+	}{
+		{"customer owns order", orderCustomer, "/orders/:id", "GET", "/orders/10", "", 1, models.RoleCustomer, http.StatusOK},
+		{"other customer", orderCustomer, "/orders/:id", "GET", "/orders/10", "", 2, models.RoleCustomer, http.StatusForbidden},
+		{"admin on order", orderCustomer, "/orders/:id", "GET", "/orders/10", "", 3, models.RoleAdmin, http.StatusOK},
+		{"missing order", orderCustomer, "/orders/:id", "GET", "/orders/99", "", 1, models.RoleCustomer, http.StatusNotFound},
+		{"invalid order ID", orderCustomer, "/orders/:id", "GET", "/orders/abc", "", 1, models.RoleCustomer, http.StatusBadRequest},
+
+		{"assigned courier", orderCourier, "/orders/:id", "POST", "/orders/10", "", 5, models.RoleCourier, http.StatusOK},
+		{"unassigned courier", orderCourier, "/orders/:id", "POST", "/orders/10", "", 6, models.RoleCourier, http.StatusForbidden},
+		{"order without courier", orderCourier, "/orders/:id", "POST", "/orders/11", "", 5, models.RoleCourier, http.StatusForbidden},
+		{"customer is not the courier", orderCourier, "/orders/:id", "POST", "/orders/10", "", 1, models.RoleCustomer, http.StatusForbidden},
+		{"admin is not the courier", orderCourier, "/orders/:id", "POST", "/orders/10", "", 3, models.RoleAdmin, http.StatusForbidden},
+		{"missing order for courier", orderCourier, "/orders/:id", "POST", "/orders/99", "", 5, models.RoleCourier, http.StatusNotFound},
+
+		{"body order customer", orderParties, "/orders/status", "PUT", "/orders/status", `{"order_id":10}`, 1, models.RoleCustomer, http.StatusOK},
+		{"body order courier", orderParties, "/orders/status", "PUT", "/orders/status", `{"order_id":10}`, 5, models.RoleCourier, http.StatusOK},
+		{"body order admin", orderParties, "/orders/status", "PUT", "/orders/status", `{"order_id":10}`, 3, models.RoleAdmin, http.StatusOK},
+		{"body order other customer", orderParties, "/orders/status", "PUT", "/orders/status", `{"order_id":10}`, 2, models.RoleCustomer, http.StatusForbidden},
+		{"body order other courier", orderParties, "/orders/status", "PUT", "/orders/status", `{"order_id":10}`, 6, models.RoleCourier, http.StatusForbidden},
+		{"body order missing", orderParties, "/orders/status", "PUT", "/orders/status", `{"order_id":99}`, 1, models.RoleCustomer, http.StatusNotFound},
+		{"body without order", orderParties, "/orders/status", "PUT", "/orders/status", `{"status":"done"}`, 1, models.RoleCustomer, http.StatusBadRequest},
+
+		{"customer owns address", addressOwner, "/addresses/:id", "PUT", "/addresses/20", "", 1, models.RoleCustomer, http.StatusOK},
+		{"other customer address", addressOwner, "/addresses/:id", "PUT", "/addresses/20", "", 2, models.RoleCustomer, http.StatusForbidden},
+		{"admin on address", addressOwner, "/addresses/:id", "PUT", "/addresses/20", "", 3, models.RoleAdmin, http.StatusOK},
+		{"missing address", addressOwner, "/addresses/:id", "PUT", "/addresses/99", "", 1, models.RoleCustomer, http.StatusNotFound},
+
+		{"user itself", self, "/users/:id", "GET", "/users/1", "", 1, models.RoleCustomer, http.StatusOK},
+		{"other user", self, "/users/:id", "GET", "/users/2", "", 1, models.RoleCustomer, http.StatusForbidden},
+		{"admin on user", self, "/users/:id", "GET", "/users/2", "", 3, models.RoleAdmin, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := policyRequest(tt.guard, tt.route, tt.method, tt.path, tt.body, tt.userID, tt.role)
+			if got != tt.want {
+				t.Errorf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBodyFieldKeepsBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/payments", func(c *gin.Context) {
+		id, err := BodyField("order_id")(c)
+		if err != nil || id != 10 {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		var body struct {
+			OrderID uint   `json:"order_id"`
+			Method  string `json:"method"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil || body.OrderID != 10 || body.Method != "cash" {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest("POST", "/payments", strings.NewReader(`{"order_id":10,"method":"cash"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
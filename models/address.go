@@ -1,6 +1,7 @@
 package models
 
 import (
+	"github.com/raihansyahrin/backend_laundry_app.git/geo"
 	"gorm.io/gorm"
 )
 
@@ -17,6 +18,27 @@ type Address struct {
 	SubDistrict   string `json:"sub_district" form:"sub_district"`
 	City          string `json:"city" gorm:"default:'Bandung'"`
 	Area          string `json:"area" gorm:"default:'Bojongsoang'"`
+	// Koordinat opsional, dipakai untuk ETA kurir dan validasi area layanan
+	Latitude  *float64 `json:"latitude" form:"latitude"`
+	Longitude *float64 `json:"longitude" form:"longitude"`
+}
+
+// Point returns the coordinates of the address, if it has both
+func (address *Address) Point() (geo.Point, bool) {
+	if address.Latitude == nil || address.Longitude == nil {
+		return geo.Point{}, false
+	}
+	return geo.Point{Lat: *address.Latitude, Lng: *address.Longitude}, true
+}
+
+// ValidCoordinates reports whether the address has either no coordinates or
+// a valid latitude and longitude pair
+func (address *Address) ValidCoordinates() bool {
+	if address.Latitude == nil && address.Longitude == nil {
+		return true
+	}
+	point, ok := address.Point()
+	return ok && point.Valid()
 }
 
 func (address *Address) BeforeCreate(tx *gorm.DB) (err error) {
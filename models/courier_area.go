@@ -0,0 +1,24 @@
+package models
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// CourierArea is an area a courier serves. District and SubDistrict narrow
+// the area down when set; empty fields match any address in the area.
+type CourierArea struct {
+	gorm.Model
+	CourierID   uint   `json:"courier_id" gorm:"index"`
+	Area        string `json:"area"`
+	District    string `json:"district"`
+	SubDistrict string `json:"sub_district"`
+}
+
+// Matches reports whether address lies in the area
+func (area *CourierArea) Matches(address Address) bool {
+	return strings.EqualFold(area.Area, address.Area) &&
+		(area.District == "" || strings.EqualFold(area.District, address.District)) &&
+		(area.SubDistrict == "" || strings.EqualFold(area.SubDistrict, address.SubDistrict))
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+)
+
+// CourierLocation is a GPS position pushed by a courier. Positions are only
+// kept for a short time, see the tracking package.
+type CourierLocation struct {
+	ID         uint      `json:"id" gorm:"primarykey"`
+	CourierID  uint      `json:"courier_id" gorm:"index:idx_courier_location_recent"`
+	Latitude   float64   `json:"latitude"`
+	Longitude  float64   `json:"longitude"`
+	Accuracy   float64   `json:"accuracy,omitempty"` // meter
+	RecordedAt time.Time `json:"recorded_at" gorm:"index:idx_courier_location_recent;index"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// DeliveryZone is the pickup and delivery tariff for addresses in an area,
+// optionally narrowed down to a district or sub-district. Orders worth at
+// least FreeAbove are delivered for free; zero means no free delivery.
+type DeliveryZone struct {
+	gorm.Model
+	Area        string  `json:"area" form:"area"`
+	District    string  `json:"district" form:"district"`
+	SubDistrict string  `json:"sub_district" form:"sub_district"`
+	Fee         float64 `json:"fee" form:"fee"`
+	FreeAbove   float64 `json:"free_above" form:"free_above"`
+}
+
+// Matches reports whether address lies in the zone
+func (zone *DeliveryZone) Matches(address Address) bool {
+	return strings.EqualFold(zone.Area, address.Area) &&
+		(zone.District == "" || strings.EqualFold(zone.District, address.District)) &&
+		(zone.SubDistrict == "" || strings.EqualFold(zone.SubDistrict, address.SubDistrict))
+}
+
+// Specificity ranks zones so the most precise match wins
+func (zone *DeliveryZone) Specificity() int {
+	switch {
+	case zone.SubDistrict != "":
+		return 3
+	case zone.District != "":
+		return 2
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Stages every garment passes: it is tagged at intake and scanned again when
+// it is handed back to the customer. Other stages are free to choose.
+const (
+	GarmentStageIntake   = "intake"
+	GarmentStageDelivery = "delivery"
+)
+
+// Garment is a single piece of clothing of an order, identified by the tag or
+// barcode attached to it at intake
+type Garment struct {
+	gorm.Model
+	OrderID        uint          `json:"order_id" gorm:"uniqueIndex:idx_garment_order_tag"`
+	Tag            string        `json:"tag" gorm:"size:64;uniqueIndex:idx_garment_order_tag"`
+	Type           string        `json:"type"`
+	Colour         string        `json:"colour"`
+	Brand          string        `json:"brand"`
+	Condition      string        `json:"condition"` // kondisi saat diterima, misalnya noda atau kancing lepas
+	Notes          string        `json:"notes"`
+	RegisteredByID uint          `json:"registered_by_id"`
+	LastStage      string        `json:"last_stage"`
+	LastScannedAt  *time.Time    `json:"last_scanned_at"`
+	Scans          []GarmentScan `json:"scans" gorm:"foreignKey:GarmentID"`
+}
+
+// GarmentScan records a garment's tag being scanned at a stage
+type GarmentScan struct {
+	gorm.Model
+	GarmentID   uint   `json:"garment_id" gorm:"index"`
+	OrderID     uint   `json:"order_id" gorm:"index"`
+	Stage       string `json:"stage"`
+	ScannedByID uint   `json:"scanned_by_id"`
+}
@@ -1,9 +1,28 @@
 package models
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
+// OrderStatus is a step of the order lifecycle. Transitions between statuses
+// are declared and enforced in the lifecycle package.
+type OrderStatus string
+
+const (
+	OrderStatusWaitingForCourier OrderStatus = "waiting for courier approval"
+	OrderStatusCourierOnTheWay   OrderStatus = "Kurir On The Way"
+	OrderStatusArrived           OrderStatus = "arrived - proses pembayaran"
+	OrderStatusWaitingForPayment OrderStatus = "waiting for payment confirmation"
+	OrderStatusInProgress        OrderStatus = "in progress"
+	OrderStatusDone              OrderStatus = "done"
+	OrderStatusDelivering        OrderStatus = "delivering"
+	OrderStatusDelivered         OrderStatus = "delivered"
+	OrderStatusCompleted         OrderStatus = "completed"
+	OrderStatusCancelled         OrderStatus = "cancelled"
+)
+
 type Order struct {
 	gorm.Model
 	CustomerID uint    `json:"customer_id"`
@@ -12,12 +31,113 @@ type Order struct {
 	ServiceID  uint    `json:"service_id"`
 	AddressID  uint    `json:"address_id"`
 	Weight     float64 `json:"weight,omitempty"`
-	Quantity   int     `json:"quantity,omitempty"` 
+	Quantity   int     `json:"quantity,omitempty"`
+	Area       float64 `json:"area,omitempty"`
 	TotalPrice float64 `json:"total_price"`
-	Status     string  `json:"status"` 
-	Address    Address `json:"address" gorm:"foreignKey:AddressID"`
-	Customer   User    `json:"customer" gorm:"foreignKey:CustomerID"`
-	Courier    User    `json:"courier" gorm:"foreignKey:CourierID"`
-	Admin      User    `json:"admin" gorm:"foreignKey:AdminID"`
-	Service    Service `json:"service" gorm:"foreignKey:ServiceID"`
+	PromoID    *uint   `json:"promo_id"`
+	PromoCode  string  `json:"promo_code"`
+	Discount   float64 `json:"discount"`
+	// DeliveryFee covers pickup and delivery, on top of TotalPrice
+	DeliveryFee      float64     `json:"delivery_fee"`
+	DeliveryFeeBasis string      `json:"delivery_fee_basis"`
+	Status           OrderStatus `json:"status"`
+	Address          Address     `json:"address" gorm:"foreignKey:AddressID"`
+	Customer         User        `json:"customer" gorm:"foreignKey:CustomerID"`
+	Courier          User        `json:"courier" gorm:"foreignKey:CourierID"`
+	Admin            User        `json:"admin" gorm:"foreignKey:AdminID"`
+	Service          Service     `json:"service" gorm:"foreignKey:ServiceID"`
+	Items            []OrderItem `json:"items" gorm:"foreignKey:OrderID"`
+
+	// Pickup and delivery windows chosen by the customer
+	PickupSlotID   *uint    `json:"pickup_slot_id"`
+	DeliverySlotID *uint    `json:"delivery_slot_id"`
+	PickupSlot     TimeSlot `json:"pickup_slot" gorm:"foreignKey:PickupSlotID"`
+	DeliverySlot   TimeSlot `json:"delivery_slot" gorm:"foreignKey:DeliverySlotID"`
+
+	// Set when the courier picks the laundry up, see scheduling.Estimate
+	EstimatedReadyAt    *time.Time `json:"estimated_ready_at"`
+	EstimatedDeliveryAt *time.Time `json:"estimated_delivery_at"`
+
+	// AssignedAt is when the dispatcher offered the order to CourierID, the
+	// courier still has to accept it
+	AssignedAt *time.Time `json:"assigned_at"`
+
+	// ProcessingStage is the outlet's current step while the order is in
+	// progress, StageEvents is the history of these steps
+	ProcessingStage string            `json:"processing_stage"`
+	StageEvents     []OrderStageEvent `json:"stage_events" gorm:"foreignKey:OrderID"`
+
+	// DeliveryCode is shown to the customer while the order is out for
+	// delivery; the courier needs it to confirm the hand-over
+	DeliveryCode         string `json:"-"`
+	DeliveryCodeAttempts int    `json:"-"`
+
+	// Snapshot of the service of orders created before order lines existed,
+	// see LegacyItem. New orders keep their snapshots on Items.
+	ServiceTitle          string       `json:"service_title"`
+	ServiceCategory       string       `json:"service_category"`
+	ServiceUnit           string       `json:"service_unit"`
+	ServicePrice          float64      `json:"service_price"`
+	ServiceMinimumCharge  float64      `json:"service_minimum_charge"`
+	ServiceWeightStep     float64      `json:"service_weight_step"`
+	ServiceWeightRounding string       `json:"service_weight_rounding"`
+	Addons                []OrderAddon `json:"addons" gorm:"foreignKey:OrderID"`
+
+	// Charges is the itemized price breakdown that adds up to TotalPrice
+	Charges []OrderCharge `json:"charges" gorm:"foreignKey:OrderID"`
+
+	Payments []Payment `json:"payments" gorm:"foreignKey:OrderID"`
+	Refunds  []Refund  `json:"refunds" gorm:"foreignKey:OrderID"`
+}
+
+// OrderAddon is a snapshot of a service add-on chosen for an order line
+type OrderAddon struct {
+	gorm.Model
+	OrderID        uint    `json:"order_id" gorm:"index"`
+	OrderItemID    *uint   `json:"order_item_id" gorm:"index"`
+	ServiceAddonID uint    `json:"service_addon_id"`
+	Code           string  `json:"code"`
+	Name           string  `json:"name"`
+	Price          float64 `json:"price"`
+	PerUnit        bool    `json:"per_unit"`
+}
+
+const (
+	ChargeService = "service"
+	ChargeMinimum = "minimum_charge"
+	ChargeAddon   = "addon"
+)
+
+// OrderCharge is one line of an order's price breakdown
+type OrderCharge struct {
+	gorm.Model
+	OrderID     uint    `json:"order_id" gorm:"index"`
+	OrderItemID *uint   `json:"order_item_id"`
+	Kind        string  `json:"kind"`
+	Description string  `json:"description"`
+	Quantity    float64 `json:"quantity"`
+	Unit        string  `json:"unit"`
+	UnitPrice   float64 `json:"unit_price"`
+	Amount      float64 `json:"amount"`
+}
+
+// AmountDue is what the customer pays: TotalPrice minus the promo discount,
+// plus the delivery fee
+func (order *Order) AmountDue() float64 {
+	if order.Discount >= order.TotalPrice {
+		return order.DeliveryFee
+	}
+	return order.TotalPrice - order.Discount + order.DeliveryFee
+}
+
+// SnapshotService copies the pricing-relevant fields of service onto the order
+func (order *Order) SnapshotService(service Service) {
+	order.ServiceID = service.ID
+	order.ServiceTitle = service.Title
+	order.ServiceCategory = service.Category
+	order.ServiceUnit = service.EffectiveUnit()
+	order.ServicePrice = service.Price
+	order.ServiceMinimumCharge = service.MinimumCharge
+	order.ServiceWeightStep = service.WeightStep
+	order.ServiceWeightRounding = service.WeightRounding
 }
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// OrderItem is one service line of an order. The service is snapshotted at
+// order creation, the measurement is recorded by the courier at pickup.
+type OrderItem struct {
+	gorm.Model
+	OrderID               uint         `json:"order_id" gorm:"index"`
+	ServiceID             uint         `json:"service_id"`
+	ServiceTitle          string       `json:"service_title"`
+	ServiceCategory       string       `json:"service_category"`
+	ServiceUnit           string       `json:"service_unit"`
+	UnitPrice             float64      `json:"unit_price"`
+	ServiceMinimumCharge  float64      `json:"service_minimum_charge"`
+	ServiceWeightStep     float64      `json:"service_weight_step"`
+	ServiceWeightRounding string       `json:"service_weight_rounding"`
+	Weight                float64      `json:"weight,omitempty"`
+	Quantity              int          `json:"quantity,omitempty"`
+	Area                  float64      `json:"area,omitempty"`
+	Subtotal              float64      `json:"subtotal"`
+	Addons                []OrderAddon `json:"addons" gorm:"foreignKey:OrderItemID"`
+}
+
+// SnapshotService copies the pricing-relevant fields of service onto the line
+func (item *OrderItem) SnapshotService(service Service) {
+	item.ServiceID = service.ID
+	item.ServiceTitle = service.Title
+	item.ServiceCategory = service.Category
+	item.ServiceUnit = service.EffectiveUnit()
+	item.UnitPrice = service.Price
+	item.ServiceMinimumCharge = service.MinimumCharge
+	item.ServiceWeightStep = service.WeightStep
+	item.ServiceWeightRounding = service.WeightRounding
+}
+
+// LegacyItem turns an order created before order lines existed into its
+// single line, using the service snapshot stored on the order.
+func (order *Order) LegacyItem() OrderItem {
+	return OrderItem{
+		OrderID:               order.ID,
+		ServiceID:             order.ServiceID,
+		ServiceTitle:          order.ServiceTitle,
+		ServiceCategory:       order.ServiceCategory,
+		ServiceUnit:           order.ServiceUnit,
+		UnitPrice:             order.ServicePrice,
+		ServiceMinimumCharge:  order.ServiceMinimumCharge,
+		ServiceWeightStep:     order.ServiceWeightStep,
+		ServiceWeightRounding: order.ServiceWeightRounding,
+		Weight:                order.Weight,
+		Quantity:              order.Quantity,
+		Area:                  order.Area,
+		Addons:                order.Addons,
+	}
+}
@@ -0,0 +1,32 @@
+package models
+
+import "gorm.io/gorm"
+
+// Stages at which a courier documents the hand-over of laundry
+const (
+	ProofStagePickup   = "pickup"
+	ProofStageDelivery = "delivery"
+)
+
+// Kinds of proof files
+const (
+	ProofKindPhoto     = "photo"
+	ProofKindSignature = "signature"
+)
+
+// OrderProof is a photo or signature taken by the courier when picking up or
+// delivering an order. It belongs to the status event that was current when
+// it was uploaded, so it shows up in the order history. The file itself is
+// kept in storage under StorageKey.
+type OrderProof struct {
+	gorm.Model
+	OrderID       uint   `json:"order_id" gorm:"index"`
+	StatusEventID *uint  `json:"status_event_id" gorm:"index"`
+	Stage         string `json:"stage"`
+	Kind          string `json:"kind"`
+	StorageKey    string `json:"-"`
+	ContentType   string `json:"content_type"`
+	Size          int64  `json:"size"`
+	RecipientName string `json:"recipient_name"`
+	UploadedByID  uint   `json:"uploaded_by_id"`
+}
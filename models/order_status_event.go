@@ -0,0 +1,19 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// OrderStatusEvent records a single status change of an order, CreatedAt is
+// the moment the change happened.
+type OrderStatusEvent struct {
+	gorm.Model
+	OrderID    uint         `json:"order_id" gorm:"index"`
+	FromStatus OrderStatus  `json:"from_status"`
+	ToStatus   OrderStatus  `json:"to_status"`
+	ActorID    *uint        `json:"actor_id"` // nil untuk perubahan oleh sistem
+	ActorRole  string       `json:"actor_role"`
+	Note       string       `json:"note"`
+	Actor      User         `json:"actor" gorm:"foreignKey:ActorID"`
+	Proofs     []OrderProof `json:"proofs" gorm:"foreignKey:StatusEventID"`
+}
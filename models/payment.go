@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	PaymentMethodCash = "cash"
+	PaymentMethodQRIS = "qris"
+)
+
+// Payment statuses, matching the statuses of the payment package
+const (
+	PaymentStatusPending  = "pending"
+	PaymentStatusPaid     = "paid"
+	PaymentStatusExpired  = "expired"
+	PaymentStatusFailed   = "failed"
+	PaymentStatusRefunded = "refunded"
+)
+
+// Payment is one attempt to collect money for an order, by cash or through
+// a payment provider.
+type Payment struct {
+	gorm.Model
+	OrderID           uint       `json:"order_id" gorm:"index"`
+	Method            string     `json:"method"`
+	Provider          string     `json:"provider"`
+	Reference         string     `json:"reference" gorm:"size:64;uniqueIndex"`
+	ProviderReference string     `json:"provider_reference" gorm:"size:128;index"`
+	Amount            float64    `json:"amount"`
+	Status            string     `json:"status"`
+	QRCode            string     `json:"qr_code" gorm:"type:text"`
+	ExpiresAt         *time.Time `json:"expires_at"`
+	PaidAt            *time.Time `json:"paid_at"`
+	CollectedByID     *uint      `json:"collected_by_id"`
+	RawResponse       string     `json:"-" gorm:"type:text"`
+}
+
+// NewCashPayment returns a settled cash payment of the amount due on order
+func NewCashPayment(order *Order, reference string, collectedByID *uint) Payment {
+	now := time.Now()
+	return Payment{
+		OrderID:       order.ID,
+		Method:        PaymentMethodCash,
+		Provider:      PaymentMethodCash,
+		Reference:     reference,
+		Amount:        order.AmountDue(),
+		Status:        PaymentStatusPaid,
+		PaidAt:        &now,
+		CollectedByID: collectedByID,
+	}
+}
+
+// IsSettled reports whether the money of the payment has been collected, even
+// if it was refunded afterwards
+func (payment *Payment) IsSettled() bool {
+	return payment.Status == PaymentStatusPaid || payment.Status == PaymentStatusRefunded
+}
+
+// PaidAmount is what was collected for the order, before refunds. Payments
+// must be loaded.
+func (order *Order) PaidAmount() float64 {
+	var total float64
+	for _, payment := range order.Payments {
+		if payment.IsSettled() {
+			total += payment.Amount
+		}
+	}
+	return total
+}
+
+// NetPaid is what the customer paid for the order minus what was refunded.
+// Payments and Refunds must be loaded.
+func (order *Order) NetPaid() float64 {
+	total := order.PaidAmount()
+	for _, refund := range order.Refunds {
+		if refund.Status == RefundStatusSucceeded {
+			total -= refund.Amount
+		}
+	}
+	return total
+}
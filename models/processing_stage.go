@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ProcessingStage is a step of the outlet's work on an order while it is in
+// progress, such as washing or ironing. Stages run in Position order.
+type ProcessingStage struct {
+	gorm.Model
+	Code     string `json:"code" gorm:"size:50;index"`
+	Name     string `json:"name"`
+	Position int    `json:"position"`
+	Active   bool   `json:"active"`
+}
+
+// OrderStageEvent records an order entering a processing stage.
+// CompletedAt is set when the order moves on.
+type OrderStageEvent struct {
+	gorm.Model
+	OrderID     uint       `json:"order_id" gorm:"index"`
+	Stage       string     `json:"stage"`
+	StartedAt   time.Time  `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	StartedByID *uint      `json:"started_by_id"`
+}
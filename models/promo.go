@@ -0,0 +1,80 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	PromoPercent = "percent"
+	PromoFixed   = "fixed"
+)
+
+// Promo is a discount code customers can apply to an order. Zero limits and
+// an empty validity window mean "unlimited".
+type Promo struct {
+	gorm.Model
+	Code           string     `json:"code" form:"code" gorm:"size:32;uniqueIndex"`
+	Description    string     `json:"description" form:"description"`
+	Type           string     `json:"type" form:"type"` // "percent" or "fixed"
+	Value          float64    `json:"value" form:"value"`
+	MaxDiscount    float64    `json:"max_discount" form:"max_discount"` // cap for percent promos
+	MinOrderValue  float64    `json:"min_order_value" form:"min_order_value"`
+	ValidFrom      *time.Time `json:"valid_from" form:"valid_from"`
+	ValidUntil     *time.Time `json:"valid_until" form:"valid_until"`
+	Weekdays       string     `json:"weekdays" form:"weekdays"` // e.g. "1,2,3,4,5", 0 is Sunday
+	UsageLimit     int        `json:"usage_limit" form:"usage_limit"`
+	PerUserLimit   int        `json:"per_user_limit" form:"per_user_limit"`
+	FirstOrderOnly bool       `json:"first_order_only" form:"first_order_only"`
+	Categories     string     `json:"categories" form:"categories"` // comma separated service categories
+	Active         bool       `json:"active" form:"active"`
+}
+
+func (promo *Promo) BeforeSave(tx *gorm.DB) (err error) {
+	promo.Code = strings.ToUpper(strings.TrimSpace(promo.Code))
+	return
+}
+
+// AppliesToCategory reports whether the promo discounts services of category
+func (promo *Promo) AppliesToCategory(category string) bool {
+	if strings.TrimSpace(promo.Categories) == "" {
+		return true
+	}
+	for _, c := range strings.Split(promo.Categories, ",") {
+		if strings.EqualFold(strings.TrimSpace(c), category) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidOn reports whether the promo can be used at t
+func (promo *Promo) ValidOn(t time.Time) bool {
+	if promo.ValidFrom != nil && t.Before(*promo.ValidFrom) {
+		return false
+	}
+	if promo.ValidUntil != nil && t.After(*promo.ValidUntil) {
+		return false
+	}
+	if strings.TrimSpace(promo.Weekdays) == "" {
+		return true
+	}
+	for _, day := range strings.Split(promo.Weekdays, ",") {
+		if d, err := strconv.Atoi(strings.TrimSpace(day)); err == nil && time.Weekday(d) == t.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+// PromoRedemption records that a promo is applied to an order, it is used to
+// enforce the promo's usage limits.
+type PromoRedemption struct {
+	gorm.Model
+	PromoID uint `json:"promo_id" gorm:"index"`
+	UserID  uint `json:"user_id" gorm:"index"`
+	OrderID uint `json:"order_id" gorm:"index"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// ReconciliationReport compares, for one day, the money providers settled
+// with the orders that were marked paid. Mismatches holds a JSON list of the
+// orders where both sides differ.
+type ReconciliationReport struct {
+	gorm.Model
+	Date            string  `json:"date" gorm:"size:10;uniqueIndex"` // YYYY-MM-DD
+	SettledCount    int     `json:"settled_count"`
+	SettledAmount   float64 `json:"settled_amount"`
+	PaidOrderCount  int     `json:"paid_order_count"`
+	PaidOrderAmount float64 `json:"paid_order_amount"`
+	Difference      float64 `json:"difference"`
+	Mismatches      string  `json:"mismatches" gorm:"type:text"`
+}
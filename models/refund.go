@@ -0,0 +1,32 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+const (
+	RefundStatusPending   = "pending"
+	RefundStatusSucceeded = "succeeded"
+	RefundStatusFailed    = "failed"
+)
+
+// Refund returns part or all of a payment to the customer. Cash refunds are
+// handed over directly, provider refunds go through the provider's API.
+type Refund struct {
+	gorm.Model
+	OrderID           uint    `json:"order_id" gorm:"index"`
+	PaymentID         uint    `json:"payment_id" gorm:"index"`
+	Method            string  `json:"method"`
+	Amount            float64 `json:"amount"`
+	Reason            string  `json:"reason"`
+	ApprovedByID      uint    `json:"approved_by_id"`
+	Status            string  `json:"status"`
+	ProviderReference string  `json:"provider_reference"`
+	RawResponse       string  `json:"-" gorm:"type:text"`
+}
+
+// Counts reports whether the refund is, or may still become, money returned
+// to the customer
+func (refund *Refund) Counts() bool {
+	return refund.Status != RefundStatusFailed
+}
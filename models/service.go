@@ -4,10 +4,94 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	UnitKg          = "kg"
+	UnitPiece       = "piece"
+	UnitPair        = "pair"
+	UnitSquareMeter = "m2"
+)
+
+// Units is the fixed set of units a service can be priced by
+var Units = []string{UnitKg, UnitPiece, UnitPair, UnitSquareMeter}
+
+const (
+	RoundingNone    = "none"
+	RoundingUp      = "up"
+	RoundingNearest = "nearest"
+)
+
 type Service struct {
 	gorm.Model
 	Title    string  `json:"title" form:"title"`
-	Time     int     `json:"time" form:"time"`
+	Time     int     `json:"time" form:"time"` // processing time in working hours
 	Price    float64 `json:"price" form:"price"`
 	Category string  `json:"category" form:"category"`
+	Unit     string  `json:"unit" form:"unit"`
+	// MinimumCharge is the least an order of this service costs before add-ons
+	MinimumCharge float64 `json:"minimum_charge" form:"minimum_charge"`
+	// WeightStep and WeightRounding control how a measured weight is billed,
+	// e.g. a step of 0.5 rounded up bills 2.1 kg as 2.5 kg
+	WeightStep     float64        `json:"weight_step" form:"weight_step"`
+	WeightRounding string         `json:"weight_rounding" form:"weight_rounding"`
+	Addons         []ServiceAddon `json:"addons" gorm:"foreignKey:ServiceID"`
+}
+
+// EffectiveUnit returns the unit the service is priced by. Services created
+// before units existed are priced per piece for "Laundry Satuan" and per kg
+// otherwise.
+func (service *Service) EffectiveUnit() string {
+	if service.Unit != "" {
+		return service.Unit
+	}
+	if service.Category == "Laundry Satuan" {
+		return UnitPiece
+	}
+	return UnitKg
+}
+
+func (service *Service) BeforeSave(tx *gorm.DB) (err error) {
+	service.Unit = service.EffectiveUnit()
+	if service.WeightRounding == "" {
+		service.WeightRounding = RoundingNone
+	}
+	return
+}
+
+// IsValidUnit reports whether unit is one of Units
+func IsValidUnit(unit string) bool {
+	for _, u := range Units {
+		if u == unit {
+			return true
+		}
+	}
+	return false
+}
+
+// ServiceAddon is an optional extra for a service such as express, perfume,
+// ironing or hanger. PerUnit add-ons are charged for every billed unit,
+// the others once per order.
+type ServiceAddon struct {
+	gorm.Model
+	ServiceID uint    `json:"service_id" gorm:"index"`
+	Code      string  `json:"code" form:"code"`
+	Name      string  `json:"name" form:"name"`
+	Price     float64 `json:"price" form:"price"`
+	PerUnit   bool    `json:"per_unit" form:"per_unit"`
+	// Time, when set, replaces the processing time of the service in hours,
+	// e.g. 6 for an express add-on
+	Time int `json:"time" form:"time"`
+}
+
+// ServicePriceHistory is a version of a service in the catalog, written every
+// time an admin creates or changes it.
+type ServicePriceHistory struct {
+	gorm.Model
+	ServiceID     uint    `json:"service_id" gorm:"index"`
+	Title         string  `json:"title"`
+	Category      string  `json:"category"`
+	Unit          string  `json:"unit"`
+	Price         float64 `json:"price"`
+	PreviousPrice float64 `json:"previous_price"`
+	ChangedByID   uint    `json:"changed_by_id"`
+	ChangedBy     User    `json:"changed_by" gorm:"foreignKey:ChangedByID"`
 }
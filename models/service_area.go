@@ -0,0 +1,45 @@
+package models
+
+import (
+	"encoding/json"
+
+	"github.com/raihansyahrin/backend_laundry_app.git/geo"
+	"gorm.io/gorm"
+)
+
+const (
+	ServiceAreaRadius  = "radius"
+	ServiceAreaPolygon = "polygon"
+)
+
+// ServiceArea is the region an outlet serves, either a radius around the
+// outlet or a polygon. Polygon holds a JSON list of points.
+type ServiceArea struct {
+	gorm.Model
+	Outlet    string  `json:"outlet"`
+	Kind      string  `json:"kind"`
+	CenterLat float64 `json:"center_lat"`
+	CenterLng float64 `json:"center_lng"`
+	RadiusKm  float64 `json:"radius_km"`
+	Polygon   string  `json:"-" gorm:"type:text"`
+	Active    bool    `json:"active"`
+}
+
+// Points decodes Polygon
+func (area *ServiceArea) Points() ([]geo.Point, error) {
+	var points []geo.Point
+	if area.Polygon == "" {
+		return points, nil
+	}
+	err := json.Unmarshal([]byte(area.Polygon), &points)
+	return points, err
+}
+
+// Contains reports whether point lies in the area
+func (area *ServiceArea) Contains(point geo.Point) bool {
+	if area.Kind == ServiceAreaPolygon {
+		points, err := area.Points()
+		return err == nil && geo.InPolygon(point, points)
+	}
+	return geo.InRadius(point, geo.Point{Lat: area.CenterLat, Lng: area.CenterLng}, area.RadiusKm)
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Session is a login on one device. The refresh token is rotated on every
+// use; only its hash is stored. Access tokens carry the session ID so they
+// stop working as soon as the session is revoked.
+type Session struct {
+	gorm.Model
+	UserID            uint       `json:"user_id" gorm:"index"`
+	RefreshTokenHash  string     `json:"-" gorm:"size:64;uniqueIndex"`
+	PreviousTokenHash string     `json:"-" gorm:"size:64;index"`
+	UserAgent         string     `json:"user_agent"`
+	ExpiresAt         time.Time  `json:"expires_at"`
+	RevokedAt         *time.Time `json:"revoked_at"`
+}
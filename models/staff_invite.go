@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// StaffInvite lets an admin invite a courier or admin who then sets their
+// own password by redeeming the token. Only the token hash is stored.
+type StaffInvite struct {
+	gorm.Model
+	Email       string     `json:"email"`
+	Username    string     `json:"username"`
+	Role        string     `json:"role"`
+	TokenHash   string     `json:"-" gorm:"size:64;uniqueIndex"`
+	InvitedByID uint       `json:"invited_by_id"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	RedeemedAt  *time.Time `json:"redeemed_at"`
+	InvitedBy   User       `json:"invited_by" gorm:"foreignKey:InvitedByID"`
+}
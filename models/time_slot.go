@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	SlotKindPickup   = "pickup"
+	SlotKindDelivery = "delivery"
+)
+
+// Layouts of TimeSlot.Date and of its start and end times
+const (
+	SlotDateLayout = "2006-01-02"
+	SlotTimeLayout = "15:04"
+)
+
+// TimeSlot is a bookable pickup or delivery window on one day. Capacity is
+// set per slot, so it can differ per day and per area; an empty Area means
+// the slot serves every area.
+type TimeSlot struct {
+	gorm.Model
+	Kind      string `json:"kind" form:"kind" gorm:"index:idx_time_slot_lookup"`
+	Date      string `json:"date" form:"date" gorm:"size:10;index:idx_time_slot_lookup"`
+	StartTime string `json:"start_time" form:"start_time" gorm:"size:5"`
+	EndTime   string `json:"end_time" form:"end_time" gorm:"size:5"`
+	Area      string `json:"area" form:"area"`
+	Capacity  int    `json:"capacity" form:"capacity"`
+	Booked    int    `json:"booked"`
+}
+
+// Start is the moment the slot begins, in the server's time zone
+func (slot *TimeSlot) Start() (time.Time, error) {
+	return time.ParseInLocation(SlotDateLayout+" "+SlotTimeLayout, slot.Date+" "+slot.StartTime, time.Local)
+}
+
+// End is the moment the slot ends, in the server's time zone
+func (slot *TimeSlot) End() (time.Time, error) {
+	return time.ParseInLocation(SlotDateLayout+" "+SlotTimeLayout, slot.Date+" "+slot.EndTime, time.Local)
+}
+
+// Remaining is the number of orders the slot can still take
+func (slot *TimeSlot) Remaining() int {
+	if slot.Booked >= slot.Capacity {
+		return 0
+	}
+	return slot.Capacity - slot.Booked
+}
@@ -4,6 +4,34 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	RoleCustomer = "customer"
+	RoleCourier  = "courier"
+	RoleAdmin    = "admin"
+
+	// RoleSystem is used for changes made by the app itself, such as payment
+	// webhooks. It is never given to a user.
+	RoleSystem = "system"
+)
+
+// Roles is the fixed set of roles a user can have
+var Roles = []string{RoleCustomer, RoleCourier, RoleAdmin}
+
+// IsValidRole reports whether role is one of Roles
+func IsValidRole(role string) bool {
+	for _, r := range Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// IsStaffRole reports whether role can only be given by an admin
+func IsStaffRole(role string) bool {
+	return role == RoleCourier || role == RoleAdmin
+}
+
 type User struct {
 	gorm.Model
 	Username  string    `json:"username"`
@@ -0,0 +1,49 @@
+package payment
+
+import (
+	"context"
+	"log"
+
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ExpireCharges marks the pending payment attempts of an order as expired,
+// for example when the order is cancelled. It returns them so their charges
+// can be cancelled at the provider once the transaction is committed.
+func ExpireCharges(tx *gorm.DB, orderID uint) ([]models.Payment, error) {
+	var pending []models.Payment
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("order_id = ? AND status = ?", orderID, models.PaymentStatusPending).
+		Find(&pending).Error
+	if err != nil || len(pending) == 0 {
+		return nil, err
+	}
+	err = tx.Model(&models.Payment{}).
+		Where("order_id = ? AND status = ?", orderID, models.PaymentStatusPending).
+		Update("status", models.PaymentStatusExpired).Error
+	if err != nil {
+		return nil, err
+	}
+	return pending, nil
+}
+
+// CancelCharges asks the provider of every record to cancel its charge, so a
+// QR code shown to the customer can no longer be paid. Failures are only
+// logged: a charge that is paid anyway is refunded by Settle.
+func CancelCharges(ctx context.Context, records []models.Payment) {
+	for _, record := range records {
+		if record.ProviderReference == "" {
+			continue
+		}
+		provider, ok := Get(record.Provider)
+		if !ok {
+			log.Printf("payment: cannot cancel %s, provider %s is not registered", record.Reference, record.Provider)
+			continue
+		}
+		if err := provider.CancelCharge(ctx, record.ProviderReference); err != nil {
+			log.Printf("payment: failed to cancel charge %s: %v", record.Reference, err)
+		}
+	}
+}
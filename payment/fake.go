@@ -0,0 +1,169 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// FakeProvider is an in-process payment provider for tests and local
+// development. Charges stay pending until SetStatus is called. Without a
+// webhook secret every webhook is rejected.
+type FakeProvider struct {
+	WebhookSecret string
+
+	mu      sync.Mutex
+	seq     int
+	charges map[string]*Charge
+	refunds map[string]*RefundResult
+}
+
+func NewFakeProvider(webhookSecret string) *FakeProvider {
+	return &FakeProvider{WebhookSecret: webhookSecret, charges: map[string]*Charge{}, refunds: map[string]*RefundResult{}}
+}
+
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *FakeProvider) CreateCharge(ctx context.Context, req ChargeRequest) (*Charge, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.seq++
+	expiresAt := time.Now().Add(15 * time.Minute)
+	charge := &Charge{
+		Reference: fmt.Sprintf("fake-%d-%s", p.seq, req.Reference),
+		Status:    StatusPending,
+		Amount:    req.Amount,
+		ExpiresAt: &expiresAt,
+	}
+	charge.QRCode = "FAKEQRIS|" + charge.Reference
+	charge.Raw = p.raw(charge)
+	p.charges[charge.Reference] = charge
+
+	copied := *charge
+	return &copied, nil
+}
+
+func (p *FakeProvider) GetStatus(ctx context.Context, reference string) (*Charge, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	charge, ok := p.charges[reference]
+	if !ok {
+		return nil, fmt.Errorf("fake charge %q not found", reference)
+	}
+	copied := *charge
+	return &copied, nil
+}
+
+func (p *FakeProvider) VerifyWebhook(header http.Header, body []byte) (*Notification, error) {
+	if !validSignature(p.WebhookSecret, body, header.Get(SignatureHeader)) {
+		return nil, ErrInvalidSignature
+	}
+
+	var notification struct {
+		Reference string  `json:"reference"`
+		Status    Status  `json:"status"`
+		Amount    float64 `json:"amount"`
+	}
+	if err := json.Unmarshal(body, &notification); err != nil {
+		return nil, fmt.Errorf("malformed fake notification: %w", err)
+	}
+	return &Notification{
+		Reference: notification.Reference,
+		Status:    notification.Status,
+		Amount:    notification.Amount,
+		Raw:       string(body),
+	}, nil
+}
+
+func (p *FakeProvider) Refund(ctx context.Context, reference string, amount float64, reason string) (*RefundResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	charge, ok := p.charges[reference]
+	if !ok {
+		return nil, fmt.Errorf("fake charge %q not found", reference)
+	}
+	if charge.Status != StatusPaid && charge.Status != StatusRefunded {
+		return nil, fmt.Errorf("fake charge %q is %s and cannot be refunded", reference, charge.Status)
+	}
+	charge.Status = StatusRefunded
+	result := &RefundResult{
+		Reference: fmt.Sprintf("%s-refund-%.0f", reference, amount),
+		Status:    StatusRefunded,
+		Raw:       p.raw(charge),
+	}
+	p.refunds[result.Reference] = result
+
+	copied := *result
+	return &copied, nil
+}
+
+func (p *FakeProvider) RefundStatus(ctx context.Context, reference string) (*RefundResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	result, ok := p.refunds[reference]
+	if !ok {
+		return nil, fmt.Errorf("fake refund %q not found", reference)
+	}
+	copied := *result
+	return &copied, nil
+}
+
+func (p *FakeProvider) CancelCharge(ctx context.Context, reference string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	charge, ok := p.charges[reference]
+	if !ok {
+		return fmt.Errorf("fake charge %q not found", reference)
+	}
+	if charge.Status != StatusPending {
+		return fmt.Errorf("fake charge %q is %s and cannot be cancelled", reference, charge.Status)
+	}
+	charge.Status = StatusExpired
+	charge.Raw = p.raw(charge)
+	return nil
+}
+
+// SetStatus changes the status of a fake charge, simulating the customer
+// paying or the QR code expiring
+func (p *FakeProvider) SetStatus(reference string, status Status) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	charge, ok := p.charges[reference]
+	if !ok {
+		return fmt.Errorf("fake charge %q not found", reference)
+	}
+	charge.Status = status
+	charge.Raw = p.raw(charge)
+	return nil
+}
+
+// WebhookBody returns a signed notification body for a fake charge as the
+// provider would deliver it
+func (p *FakeProvider) WebhookBody(reference string) ([]byte, string, error) {
+	charge, err := p.GetStatus(context.Background(), reference)
+	if err != nil {
+		return nil, "", err
+	}
+	body := []byte(charge.Raw)
+	return body, Sign(p.WebhookSecret, body), nil
+}
+
+func (p *FakeProvider) raw(charge *Charge) string {
+	raw, _ := json.Marshal(map[string]interface{}{
+		"reference": charge.Reference,
+		"status":    charge.Status,
+		"amount":    charge.Amount,
+	})
+	return string(raw)
+}
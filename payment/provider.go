@@ -0,0 +1,146 @@
+package payment
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Status of a charge at the provider
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusPaid     Status = "paid"
+	StatusExpired  Status = "expired"
+	StatusFailed   Status = "failed"
+	StatusRefunded Status = "refunded"
+)
+
+// ChargeRequest asks a provider to collect Amount for an order. Reference is
+// our own unique reference for the attempt.
+type ChargeRequest struct {
+	OrderID     uint
+	Reference   string
+	Amount      float64
+	Description string
+}
+
+// Charge is the provider's view of a payment attempt
+type Charge struct {
+	Reference string
+	Status    Status
+	Amount    float64
+	QRCode    string
+	ExpiresAt *time.Time
+	Raw       string
+}
+
+// Notification is a verified webhook call from a provider
+type Notification struct {
+	Reference string
+	Status    Status
+	Amount    float64
+	Raw       string
+}
+
+// RefundResult is the provider's answer to a refund request
+type RefundResult struct {
+	Reference string
+	Status    Status
+	Raw       string
+}
+
+// Provider is a payment gateway able to collect and refund money
+type Provider interface {
+	Name() string
+	CreateCharge(ctx context.Context, req ChargeRequest) (*Charge, error)
+	GetStatus(ctx context.Context, reference string) (*Charge, error)
+	VerifyWebhook(header http.Header, body []byte) (*Notification, error)
+	Refund(ctx context.Context, reference string, amount float64, reason string) (*RefundResult, error)
+	RefundStatus(ctx context.Context, reference string) (*RefundResult, error)
+	CancelCharge(ctx context.Context, reference string) error
+}
+
+// ErrInvalidSignature is returned by VerifyWebhook for unsigned or forged calls
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+var (
+	mu        sync.RWMutex
+	providers = map[string]Provider{}
+	methods   = map[string]string{}
+)
+
+// Register makes a provider available under its name and as the handler of
+// a payment method such as "qris".
+func Register(method string, provider Provider) {
+	mu.Lock()
+	defer mu.Unlock()
+	providers[provider.Name()] = provider
+	methods[method] = provider.Name()
+}
+
+// Get returns the provider registered under name
+func Get(name string) (Provider, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	provider, ok := providers[name]
+	return provider, ok
+}
+
+// ForMethod returns the provider handling a payment method
+func ForMethod(method string) (Provider, bool) {
+	mu.RLock()
+	name, ok := methods[method]
+	mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	return Get(name)
+}
+
+// Setup registers the QRIS provider configured in the environment. The
+// in-process fake provider is only used when PAYMENT_PROVIDER=fake is set
+// explicitly, never in release mode, and needs FAKE_PAYMENT_WEBHOOK_SECRET.
+func Setup() error {
+	if strings.EqualFold(os.Getenv("PAYMENT_PROVIDER"), "fake") {
+		if os.Getenv("GIN_MODE") == "release" {
+			return errors.New("payment: the fake provider cannot be used in release mode")
+		}
+		secret := os.Getenv("FAKE_PAYMENT_WEBHOOK_SECRET")
+		if secret == "" {
+			return errors.New("payment: FAKE_PAYMENT_WEBHOOK_SECRET is required for the fake provider")
+		}
+		log.Println("payment: using fake QRIS provider")
+		Register("qris", NewFakeProvider(secret))
+		return nil
+	}
+
+	provider, err := NewQRISProvider(QRISConfig{
+		BaseURL:       os.Getenv("QRIS_BASE_URL"),
+		APIKey:        os.Getenv("QRIS_API_KEY"),
+		WebhookSecret: os.Getenv("QRIS_WEBHOOK_SECRET"),
+	})
+	if err != nil {
+		return fmt.Errorf("payment: %w", err)
+	}
+	Register("qris", provider)
+	return nil
+}
+
+// NewReference returns a unique merchant reference for a payment attempt
+func NewReference(orderID uint) string {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("ORD%d-%d", orderID, time.Now().UnixNano())
+	}
+	return fmt.Sprintf("ORD%d-%s", orderID, hex.EncodeToString(buf))
+}
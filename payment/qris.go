@@ -0,0 +1,197 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// QRISConfig configures the QRIS gateway client
+type QRISConfig struct {
+	BaseURL       string
+	APIKey        string
+	WebhookSecret string
+}
+
+// QRISProvider talks to the QRIS payment gateway over its REST API
+type QRISProvider struct {
+	config QRISConfig
+	client *resty.Client
+}
+
+// qrisCharge is the charge representation used by the gateway API
+type qrisCharge struct {
+	Reference string    `json:"reference"`
+	Status    string    `json:"status"`
+	Amount    float64   `json:"amount"`
+	QRString  string    `json:"qr_string"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func NewQRISProvider(config QRISConfig) (*QRISProvider, error) {
+	if config.BaseURL == "" || config.APIKey == "" || config.WebhookSecret == "" {
+		return nil, errors.New("QRIS_BASE_URL, QRIS_API_KEY and QRIS_WEBHOOK_SECRET are required")
+	}
+	client := resty.New().
+		SetBaseURL(strings.TrimRight(config.BaseURL, "/")).
+		SetAuthToken(config.APIKey).
+		SetTimeout(15 * time.Second)
+	return &QRISProvider{config: config, client: client}, nil
+}
+
+func (p *QRISProvider) Name() string {
+	return "qris"
+}
+
+func (p *QRISProvider) CreateCharge(ctx context.Context, req ChargeRequest) (*Charge, error) {
+	resp, err := p.client.R().
+		SetContext(ctx).
+		SetBody(map[string]interface{}{
+			"reference":   req.Reference,
+			"amount":      req.Amount,
+			"description": req.Description,
+		}).
+		Post("/charges")
+	if err != nil {
+		return nil, err
+	}
+	return p.parseCharge(resp)
+}
+
+func (p *QRISProvider) GetStatus(ctx context.Context, reference string) (*Charge, error) {
+	resp, err := p.client.R().
+		SetContext(ctx).
+		SetPathParam("reference", reference).
+		Get("/charges/{reference}")
+	if err != nil {
+		return nil, err
+	}
+	return p.parseCharge(resp)
+}
+
+func (p *QRISProvider) VerifyWebhook(header http.Header, body []byte) (*Notification, error) {
+	if !validSignature(p.config.WebhookSecret, body, header.Get(SignatureHeader)) {
+		return nil, ErrInvalidSignature
+	}
+
+	var notification qrisCharge
+	if err := json.Unmarshal(body, &notification); err != nil {
+		return nil, fmt.Errorf("malformed QRIS notification: %w", err)
+	}
+	return &Notification{
+		Reference: notification.Reference,
+		Status:    qrisStatus(notification.Status),
+		Amount:    notification.Amount,
+		Raw:       string(body),
+	}, nil
+}
+
+func (p *QRISProvider) Refund(ctx context.Context, reference string, amount float64, reason string) (*RefundResult, error) {
+	resp, err := p.client.R().
+		SetContext(ctx).
+		SetPathParam("reference", reference).
+		SetBody(map[string]interface{}{
+			"amount": amount,
+			"reason": reason,
+		}).
+		Post("/charges/{reference}/refunds")
+	if err != nil {
+		return nil, err
+	}
+	if resp.IsError() {
+		return nil, fmt.Errorf("QRIS refund failed with status %d: %s", resp.StatusCode(), resp.String())
+	}
+
+	var result struct {
+		Reference string `json:"reference"`
+		Status    string `json:"status"`
+	}
+	if err := json.Unmarshal(resp.Body(), &result); err != nil {
+		return nil, fmt.Errorf("malformed QRIS refund response: %w", err)
+	}
+	return &RefundResult{Reference: result.Reference, Status: qrisStatus(result.Status), Raw: resp.String()}, nil
+}
+
+func (p *QRISProvider) RefundStatus(ctx context.Context, reference string) (*RefundResult, error) {
+	resp, err := p.client.R().
+		SetContext(ctx).
+		SetPathParam("reference", reference).
+		Get("/refunds/{reference}")
+	if err != nil {
+		return nil, err
+	}
+	if resp.IsError() {
+		return nil, fmt.Errorf("QRIS refund lookup failed with status %d: %s", resp.StatusCode(), resp.String())
+	}
+
+	var result struct {
+		Reference string `json:"reference"`
+		Status    string `json:"status"`
+	}
+	if err := json.Unmarshal(resp.Body(), &result); err != nil {
+		return nil, fmt.Errorf("malformed QRIS refund response: %w", err)
+	}
+	return &RefundResult{Reference: reference, Status: qrisStatus(result.Status), Raw: resp.String()}, nil
+}
+
+func (p *QRISProvider) CancelCharge(ctx context.Context, reference string) error {
+	resp, err := p.client.R().
+		SetContext(ctx).
+		SetPathParam("reference", reference).
+		Post("/charges/{reference}/cancel")
+	if err != nil {
+		return err
+	}
+	if resp.IsError() {
+		return fmt.Errorf("QRIS cancel failed with status %d: %s", resp.StatusCode(), resp.String())
+	}
+	return nil
+}
+
+func (p *QRISProvider) parseCharge(resp *resty.Response) (*Charge, error) {
+	if resp.IsError() {
+		return nil, fmt.Errorf("QRIS request failed with status %d: %s", resp.StatusCode(), resp.String())
+	}
+
+	var charge qrisCharge
+	if err := json.Unmarshal(resp.Body(), &charge); err != nil {
+		return nil, fmt.Errorf("malformed QRIS response: %w", err)
+	}
+	if charge.Reference == "" {
+		return nil, errors.New("QRIS response has no reference")
+	}
+
+	result := &Charge{
+		Reference: charge.Reference,
+		Status:    qrisStatus(charge.Status),
+		Amount:    charge.Amount,
+		QRCode:    charge.QRString,
+		Raw:       resp.String(),
+	}
+	if !charge.ExpiresAt.IsZero() {
+		result.ExpiresAt = &charge.ExpiresAt
+	}
+	return result, nil
+}
+
+// qrisStatus maps gateway statuses onto ours
+func qrisStatus(status string) Status {
+	switch strings.ToLower(status) {
+	case "paid", "settled", "success", "completed":
+		return StatusPaid
+	case "expired":
+		return StatusExpired
+	case "failed", "cancelled", "canceled":
+		return StatusFailed
+	case "refunded", "partially_refunded":
+		return StatusRefunded
+	default:
+		return StatusPending
+	}
+}
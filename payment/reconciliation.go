@@ -0,0 +1,106 @@
+package payment
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReportDateLayout is the format of ReconciliationReport.Date
+const ReportDateLayout = "2006-01-02"
+
+// Mismatch is an order whose settled amount differs from what it was marked
+// paid for
+type Mismatch struct {
+	OrderID  uint    `json:"order_id"`
+	Settled  float64 `json:"settled"`
+	Expected float64 `json:"expected"`
+}
+
+// Reconcile builds and stores the reconciliation report for the day
+// containing day, in the server's time zone. Existing reports for that day
+// are replaced.
+func Reconcile(db *gorm.DB, day time.Time) (*models.ReconciliationReport, error) {
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.Local)
+	end := start.AddDate(0, 0, 1)
+
+	// Uang yang benar-benar diterima provider pada hari itu
+	var settled []models.Payment
+	err := db.Where("method <> ? AND status IN ? AND paid_at >= ? AND paid_at < ?",
+		models.PaymentMethodCash, []string{models.PaymentStatusPaid, models.PaymentStatusRefunded}, start, end).
+		Find(&settled).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Order yang ditandai lunas pada hari itu, oleh admin atau oleh sistem
+	var events []models.OrderStatusEvent
+	err = db.Where("to_status = ? AND created_at >= ? AND created_at < ?", models.OrderStatusInProgress, start, end).
+		Where("from_status = ? OR (from_status = ? AND actor_role = ?)",
+			models.OrderStatusWaitingForPayment, models.OrderStatusArrived, models.RoleSystem).
+		Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+
+	report := models.ReconciliationReport{Date: start.Format(ReportDateLayout)}
+	byOrder := map[uint]*Mismatch{}
+	entry := func(orderID uint) *Mismatch {
+		if byOrder[orderID] == nil {
+			byOrder[orderID] = &Mismatch{OrderID: orderID}
+		}
+		return byOrder[orderID]
+	}
+
+	for _, record := range settled {
+		report.SettledCount++
+		report.SettledAmount += record.Amount
+		entry(record.OrderID).Settled += record.Amount
+	}
+
+	if len(events) > 0 {
+		orderIDs := make([]uint, 0, len(events))
+		for _, event := range events {
+			orderIDs = append(orderIDs, event.OrderID)
+		}
+		var orders []models.Order
+		if err := db.Find(&orders, orderIDs).Error; err != nil {
+			return nil, err
+		}
+		for _, order := range orders {
+			report.PaidOrderCount++
+			report.PaidOrderAmount += order.AmountDue()
+			entry(order.ID).Expected += order.AmountDue()
+		}
+	}
+	report.Difference = report.SettledAmount - report.PaidOrderAmount
+
+	mismatches := []Mismatch{}
+	for _, m := range byOrder {
+		if m.Settled != m.Expected {
+			mismatches = append(mismatches, *m)
+		}
+	}
+	sort.Slice(mismatches, func(i, j int) bool { return mismatches[i].OrderID < mismatches[j].OrderID })
+	raw, err := json.Marshal(mismatches)
+	if err != nil {
+		return nil, err
+	}
+	report.Mismatches = string(raw)
+
+	err = db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"updated_at", "settled_count", "settled_amount", "paid_order_count", "paid_order_amount", "difference", "mismatches"}),
+	}).Create(&report).Error
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Where("date = ?", report.Date).First(&report).Error; err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
@@ -0,0 +1,224 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrNothingToRefund is returned for orders without refundable payments
+	ErrNothingToRefund = errors.New("order has no payment left to refund")
+	// ErrRefundTooLarge is returned when the amount exceeds what can be refunded
+	ErrRefundTooLarge = errors.New("refund amount exceeds the refundable amount")
+)
+
+// IsRefundError reports whether err is caused by the refund request rather
+// than by the database or the provider
+func IsRefundError(err error) bool {
+	return errors.Is(err, ErrNothingToRefund) || errors.Is(err, ErrRefundTooLarge)
+}
+
+// RefundOrder returns amount to the customer of an order, or everything that
+// is left when amount is 0. The amount is taken from the most recent payments
+// first, with one refund per payment. Refunds are reserved in a transaction
+// before the provider is called, so concurrent requests cannot refund more
+// than was paid.
+func RefundOrder(ctx context.Context, db *gorm.DB, orderID uint, amount float64, reason string, approvedByID uint) ([]models.Refund, error) {
+	if amount < 0 {
+		return nil, fmt.Errorf("%w: amount must be positive", ErrRefundTooLarge)
+	}
+
+	var refunds []models.Refund
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var payments []models.Payment
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("order_id = ? AND status IN ?", orderID, []string{models.PaymentStatusPaid, models.PaymentStatusRefunded}).
+			Order("id desc").Find(&payments).Error
+		if err != nil {
+			return err
+		}
+
+		refundable := map[uint]float64{}
+		var total float64
+		for _, record := range payments {
+			var existing []models.Refund
+			if err := tx.Where("payment_id = ?", record.ID).Find(&existing).Error; err != nil {
+				return err
+			}
+			left := record.Amount
+			for _, refund := range existing {
+				if refund.Counts() {
+					left -= refund.Amount
+				}
+			}
+			if left > 0 {
+				refundable[record.ID] = left
+				total += left
+			}
+		}
+		if total <= 0 {
+			return ErrNothingToRefund
+		}
+		if amount == 0 {
+			amount = total
+		}
+		if amount > total {
+			return fmt.Errorf("%w: %.2f requested, %.2f refundable", ErrRefundTooLarge, amount, total)
+		}
+
+		remaining := amount
+		for _, record := range payments {
+			if remaining <= 0 {
+				break
+			}
+			part := refundable[record.ID]
+			if part <= 0 {
+				continue
+			}
+			if part > remaining {
+				part = remaining
+			}
+			remaining -= part
+
+			refund := models.Refund{
+				OrderID:      orderID,
+				PaymentID:    record.ID,
+				Method:       record.Method,
+				Amount:       part,
+				Reason:       reason,
+				ApprovedByID: approvedByID,
+				Status:       models.RefundStatusPending,
+			}
+			// Uang tunai dikembalikan langsung oleh admin
+			if record.Method == models.PaymentMethodCash {
+				refund.Status = models.RefundStatusSucceeded
+			}
+			if err := tx.Create(&refund).Error; err != nil {
+				return err
+			}
+			refunds = append(refunds, refund)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Setiap refund tetap dikirim walaupun refund sebelumnya gagal
+	var errs []error
+	for i := range refunds {
+		if refunds[i].Status == models.RefundStatusPending {
+			if err := submitRefund(ctx, db, &refunds[i]); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+		if err := markRefunded(db, refunds[i].PaymentID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return refunds, errors.Join(errs...)
+}
+
+// submitRefund asks the provider of the refunded payment to return the money.
+// A refund that cannot be submitted is marked failed, so it no longer counts
+// and the money can be refunded again.
+func submitRefund(ctx context.Context, db *gorm.DB, refund *models.Refund) error {
+	var record models.Payment
+	if err := db.First(&record, refund.PaymentID).Error; err != nil {
+		refund.Status = models.RefundStatusFailed
+		refund.RawResponse = err.Error()
+		db.Save(refund)
+		return err
+	}
+
+	provider, ok := Get(record.Provider)
+	if !ok {
+		refund.Status = models.RefundStatusFailed
+		refund.RawResponse = "provider " + record.Provider + " is not registered"
+		db.Save(refund)
+		return fmt.Errorf("payment provider %q is not registered", record.Provider)
+	}
+
+	result, err := provider.Refund(ctx, record.ProviderReference, refund.Amount, refund.Reason)
+	if err != nil {
+		refund.Status = models.RefundStatusFailed
+		refund.RawResponse = err.Error()
+		db.Save(refund)
+		return err
+	}
+
+	refund.ProviderReference = result.Reference
+	return saveRefundResult(db, refund, result)
+}
+
+// SyncRefund settles a refund the provider has not finished yet: one that
+// was reserved but never submitted is submitted, one the provider reported
+// as pending is looked up again.
+func SyncRefund(ctx context.Context, db *gorm.DB, refund *models.Refund) error {
+	if refund.Status != models.RefundStatusPending {
+		return nil
+	}
+	if refund.ProviderReference == "" {
+		if refund.RawResponse != "" {
+			return fmt.Errorf("refund %d was answered without a provider reference", refund.ID)
+		}
+		if err := submitRefund(ctx, db, refund); err != nil {
+			return err
+		}
+		return markRefunded(db, refund.PaymentID)
+	}
+
+	var record models.Payment
+	if err := db.First(&record, refund.PaymentID).Error; err != nil {
+		return err
+	}
+	provider, ok := Get(record.Provider)
+	if !ok {
+		return fmt.Errorf("payment provider %q is not registered", record.Provider)
+	}
+	result, err := provider.RefundStatus(ctx, refund.ProviderReference)
+	if err != nil {
+		return err
+	}
+	if err := saveRefundResult(db, refund, result); err != nil {
+		return err
+	}
+	return markRefunded(db, refund.PaymentID)
+}
+
+// saveRefundResult stores what the provider answered about a refund
+func saveRefundResult(db *gorm.DB, refund *models.Refund, result *RefundResult) error {
+	refund.RawResponse = result.Raw
+	switch result.Status {
+	case StatusRefunded:
+		refund.Status = models.RefundStatusSucceeded
+	case StatusFailed:
+		refund.Status = models.RefundStatusFailed
+	}
+	return db.Save(refund).Error
+}
+
+// markRefunded sets a payment to refunded once all of it has been returned
+func markRefunded(db *gorm.DB, paymentID uint) error {
+	var record models.Payment
+	if err := db.First(&record, paymentID).Error; err != nil {
+		return err
+	}
+	var refunded float64
+	err := db.Model(&models.Refund{}).
+		Where("payment_id = ? AND status = ?", paymentID, models.RefundStatusSucceeded).
+		Select("COALESCE(SUM(amount), 0)").Scan(&refunded).Error
+	if err != nil {
+		return err
+	}
+	if refunded >= record.Amount && record.Status != models.PaymentStatusRefunded {
+		return db.Model(&record).Update("status", models.PaymentStatusRefunded).Error
+	}
+	return nil
+}
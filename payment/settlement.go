@@ -0,0 +1,110 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/raihansyahrin/backend_laundry_app.git/lifecycle"
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"gorm.io/gorm"
+)
+
+// ErrAmountMismatch is returned when a provider reports a payment for a
+// different amount than the one that was charged
+var ErrAmountMismatch = errors.New("paid amount does not match the charge")
+
+// settleable lists, for every status a provider may report, the stored
+// statuses it can replace. Anything else is a replayed or out-of-order
+// notification and is ignored. A late payment still wins over an expired or
+// failed attempt because the customer's money has been collected.
+var settleable = map[Status][]string{
+	StatusPaid:    {models.PaymentStatusPending, models.PaymentStatusExpired, models.PaymentStatusFailed},
+	StatusExpired: {models.PaymentStatusPending},
+	StatusFailed:  {models.PaymentStatusPending},
+}
+
+// Settle records the status a provider reported for a payment attempt. The
+// first time an attempt is paid the order waiting for it moves to in
+// progress; when it expires or fails the order goes back to awaiting payment.
+// Money paid for an order that was cancelled in the meantime is refunded.
+// It reports whether the stored payment changed, so callers can
+// tell a new notification from a replay.
+func Settle(db *gorm.DB, record *models.Payment, status Status, amount float64, raw string) (bool, error) {
+	from, ok := settleable[status]
+	if !ok {
+		return false, nil
+	}
+	if status == StatusPaid && amount != 0 && amount != record.Amount {
+		return false, fmt.Errorf("%w: charged %.2f, paid %.2f", ErrAmountMismatch, record.Amount, amount)
+	}
+
+	changed, cancelled := false, false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{"status": string(status), "raw_response": raw}
+		if status == StatusPaid {
+			updates["paid_at"] = time.Now()
+		}
+		// Update bersyarat agar notifikasi yang dikirim bersamaan hanya diproses sekali
+		result := tx.Model(&models.Payment{}).Where("id = ? AND status IN ?", record.ID, from).Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		changed = true
+
+		var order models.Order
+		if err := tx.First(&order, record.OrderID).Error; err != nil {
+			return err
+		}
+		if status != StatusPaid {
+			return reopenOrder(tx, &order, record, status)
+		}
+		if order.Status == models.OrderStatusCancelled {
+			cancelled = true
+			return nil
+		}
+		// Order yang sudah lanjut (misalnya dibayar tunai) tidak diubah lagi
+		if order.Status != models.OrderStatusWaitingForPayment && order.Status != models.OrderStatusArrived {
+			return nil
+		}
+		note := fmt.Sprintf("paid via %s, reference %s", record.Provider, record.Reference)
+		return lifecycle.TransitionWithNote(tx, &order, models.OrderStatusInProgress, lifecycle.System, note)
+	})
+	if err != nil {
+		return false, err
+	}
+	if cancelled {
+		// Refund yang gagal tetap tercatat dan bisa diulang dari endpoint refunds
+		reason := fmt.Sprintf("Paid via %s after the order was cancelled", record.Provider)
+		if _, err := RefundOrder(context.Background(), db, record.OrderID, 0, reason, 0); err != nil && !errors.Is(err, ErrNothingToRefund) {
+			log.Printf("payment: failed to refund %s on cancelled order %d: %v", record.Reference, record.OrderID, err)
+		}
+	}
+	if changed {
+		return true, db.First(record, record.ID).Error
+	}
+	return false, nil
+}
+
+// reopenOrder moves an order whose payment expired or failed back to the
+// awaiting-payment state, so the customer can pay again, unless another
+// attempt for it is still pending or already paid.
+func reopenOrder(tx *gorm.DB, order *models.Order, record *models.Payment, status Status) error {
+	if order.Status != models.OrderStatusWaitingForPayment {
+		return nil
+	}
+	var open int64
+	err := tx.Model(&models.Payment{}).
+		Where("order_id = ? AND id <> ? AND status IN ?", order.ID, record.ID, []string{models.PaymentStatusPending, models.PaymentStatusPaid}).
+		Count(&open).Error
+	if err != nil || open > 0 {
+		return err
+	}
+	note := fmt.Sprintf("payment %s %s", record.Reference, status)
+	return lifecycle.TransitionWithNote(tx, order, models.OrderStatusArrived, lifecycle.System, note)
+}
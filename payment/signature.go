@@ -0,0 +1,32 @@
+package payment
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// SignatureHeader carries the hex HMAC-SHA256 of the webhook body
+const SignatureHeader = "X-Signature"
+
+// Sign returns the signature of body for secret, as sent in SignatureHeader
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func validSignature(secret string, body []byte, signature string) bool {
+	if secret == "" {
+		return false
+	}
+	expected, err := hex.DecodeString(Sign(secret, body))
+	if err != nil {
+		return false
+	}
+	given, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(expected, given)
+}
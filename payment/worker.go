@@ -0,0 +1,151 @@
+package payment
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"gorm.io/gorm"
+)
+
+// Defaults used when the environment does not configure the worker
+const (
+	DefaultPollInterval = time.Minute
+	DefaultExpiry       = 15 * time.Minute
+)
+
+// Worker polls providers for pending payments so orders move on even when a
+// webhook is lost, expires stale QR codes, settles pending refunds and writes
+// the daily reconciliation report.
+type Worker struct {
+	DB           *gorm.DB
+	PollInterval time.Duration
+	Expiry       time.Duration
+
+	lastReport string
+}
+
+// NewWorkerFromEnv configures a worker from PAYMENT_POLL_INTERVAL and
+// PAYMENT_EXPIRY, both Go durations such as "30s" or "15m".
+func NewWorkerFromEnv(db *gorm.DB) *Worker {
+	return &Worker{
+		DB:           db,
+		PollInterval: durationFromEnv("PAYMENT_POLL_INTERVAL", DefaultPollInterval),
+		Expiry:       durationFromEnv("PAYMENT_EXPIRY", DefaultExpiry),
+	}
+}
+
+func durationFromEnv(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		log.Printf("payment: invalid %s %q, using %s", key, value, fallback)
+		return fallback
+	}
+	return duration
+}
+
+// Start runs the worker in the background until ctx is done
+func (w *Worker) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(w.PollInterval)
+		defer ticker.Stop()
+		for {
+			w.RunOnce(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RunOnce polls every pending payment and refund and writes yesterday's
+// report if it has not been written yet
+func (w *Worker) RunOnce(ctx context.Context) {
+	var pending []models.Payment
+	if err := w.DB.Where("method <> ? AND status = ?", models.PaymentMethodCash, models.PaymentStatusPending).Find(&pending).Error; err != nil {
+		log.Printf("payment: failed to load pending payments: %v", err)
+		return
+	}
+	for i := range pending {
+		if err := w.poll(ctx, &pending[i]); err != nil {
+			log.Printf("payment: failed to poll payment %s: %v", pending[i].Reference, err)
+		}
+	}
+
+	w.syncRefunds(ctx)
+	w.reportYesterday()
+}
+
+// syncRefunds submits refunds that were reserved but never sent and asks the
+// provider about refunds it reported as pending
+func (w *Worker) syncRefunds(ctx context.Context) {
+	var pending []models.Refund
+	if err := w.DB.Where("method <> ? AND status = ?", models.PaymentMethodCash, models.RefundStatusPending).Find(&pending).Error; err != nil {
+		log.Printf("payment: failed to load pending refunds: %v", err)
+		return
+	}
+	for i := range pending {
+		// Refund yang baru dibuat masih dikirim oleh RefundOrder
+		if time.Since(pending[i].CreatedAt) < w.PollInterval {
+			continue
+		}
+		if err := SyncRefund(ctx, w.DB, &pending[i]); err != nil {
+			log.Printf("payment: failed to sync refund %d: %v", pending[i].ID, err)
+		}
+	}
+}
+
+// poll asks the provider for the status of a pending payment and expires it
+// once it is older than the configured window
+func (w *Worker) poll(ctx context.Context, record *models.Payment) error {
+	provider, ok := Get(record.Provider)
+	if ok && record.ProviderReference != "" {
+		charge, err := provider.GetStatus(ctx, record.ProviderReference)
+		if err != nil {
+			log.Printf("payment: provider status for %s unavailable: %v", record.Reference, err)
+		} else if charge.Status != StatusPending {
+			_, err := Settle(w.DB, record, charge.Status, charge.Amount, charge.Raw)
+			return err
+		}
+	}
+
+	now := time.Now()
+	expired := now.After(record.CreatedAt.Add(w.Expiry))
+	if record.ExpiresAt != nil && now.After(*record.ExpiresAt) {
+		expired = true
+	}
+	if !expired {
+		return nil
+	}
+	_, err := Settle(w.DB, record, StatusExpired, 0, record.RawResponse)
+	return err
+}
+
+func (w *Worker) reportYesterday() {
+	yesterday := time.Now().AddDate(0, 0, -1)
+	date := yesterday.Format(ReportDateLayout)
+	if w.lastReport == date {
+		return
+	}
+
+	var count int64
+	if err := w.DB.Model(&models.ReconciliationReport{}).Where("date = ?", date).Count(&count).Error; err != nil {
+		log.Printf("payment: failed to check reconciliation report: %v", err)
+		return
+	}
+	if count == 0 {
+		if _, err := Reconcile(w.DB, yesterday); err != nil {
+			log.Printf("payment: failed to build reconciliation report for %s: %v", date, err)
+			return
+		}
+	}
+	w.lastReport = date
+}
@@ -0,0 +1,153 @@
+package pricing
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/raihansyahrin/backend_laundry_app.git/geo"
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"gorm.io/gorm"
+)
+
+// DeliveryQuote is the pickup and delivery fee of an order and how it was
+// worked out
+type DeliveryQuote struct {
+	Fee   float64
+	Basis string
+}
+
+func floatFromEnv(key string) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return 0
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil || parsed < 0 {
+		log.Printf("pricing: invalid %s %q", key, value)
+		return 0
+	}
+	return parsed
+}
+
+// deliveryRates are the delivery settings read from the environment
+type deliveryRates struct {
+	DefaultFee float64
+	FreeAbove  float64
+	PerKm      float64
+	BaseFee    float64
+}
+
+func deliveryRatesFromEnv() deliveryRates {
+	return deliveryRates{
+		DefaultFee: floatFromEnv("DELIVERY_DEFAULT_FEE"),
+		FreeAbove:  floatFromEnv("DELIVERY_FREE_ABOVE"),
+		PerKm:      floatFromEnv("DELIVERY_FEE_PER_KM"),
+		BaseFee:    floatFromEnv("DELIVERY_BASE_FEE"),
+	}
+}
+
+// QuoteDelivery prices pickup and delivery to address for an order worth
+// subtotal. With DELIVERY_FEE_PER_KM set and coordinates on the address, the
+// fee is DELIVERY_BASE_FEE plus the distance to the nearest outlet; otherwise
+// the most specific DeliveryZone applies, then DELIVERY_DEFAULT_FEE.
+// DELIVERY_FREE_ABOVE, or the zone's own threshold, waives the fee.
+func QuoteDelivery(tx *gorm.DB, address models.Address, subtotal float64) (DeliveryQuote, error) {
+	rates := deliveryRatesFromEnv()
+
+	var zones []models.DeliveryZone
+	if err := tx.Where("area = ?", address.Area).Find(&zones).Error; err != nil {
+		return DeliveryQuote{}, err
+	}
+
+	// Outlet hanya perlu dimuat kalau tarif per km dipakai
+	var outlets []models.ServiceArea
+	if _, ok := address.Point(); ok && rates.PerKm > 0 {
+		if err := tx.Where("active = ? AND kind = ?", true, models.ServiceAreaRadius).Find(&outlets).Error; err != nil {
+			return DeliveryQuote{}, err
+		}
+	}
+
+	return quoteDelivery(rates, address, subtotal, zones, outlets), nil
+}
+
+// quoteDelivery is QuoteDelivery with the zones of the address's area and the
+// active radius service areas, whose centers are the outlets, already loaded
+func quoteDelivery(rates deliveryRates, address models.Address, subtotal float64, zones []models.DeliveryZone, outlets []models.ServiceArea) DeliveryQuote {
+	quote := DeliveryQuote{Fee: rates.DefaultFee, Basis: "default"}
+	freeAbove := rates.FreeAbove
+
+	var zone *models.DeliveryZone
+	for i := range zones {
+		if zones[i].Matches(address) && (zone == nil || zones[i].Specificity() > zone.Specificity()) {
+			zone = &zones[i]
+		}
+	}
+	if zone != nil {
+		quote = DeliveryQuote{Fee: zone.Fee, Basis: "zone " + zoneName(zone)}
+		if zone.FreeAbove > 0 {
+			freeAbove = zone.FreeAbove
+		}
+	}
+
+	if point, ok := address.Point(); ok && rates.PerKm > 0 {
+		if distance, outlet := nearestOutlet(point, outlets); outlet != "" {
+			quote = DeliveryQuote{
+				Fee:   money(rates.BaseFee + rates.PerKm*distance),
+				Basis: fmt.Sprintf("%.1f km from %s", distance, outlet),
+			}
+		}
+	}
+
+	if freeAbove > 0 && subtotal >= freeAbove {
+		return DeliveryQuote{Fee: 0, Basis: fmt.Sprintf("free for orders from %.0f", freeAbove)}
+	}
+	return quote
+}
+
+// nearestOutlet returns the distance to the closest outlet, taken as the
+// center of a radius service area
+func nearestOutlet(point geo.Point, areas []models.ServiceArea) (float64, string) {
+	nearest, outlet := 0.0, ""
+	for _, area := range areas {
+		distance := geo.DistanceKm(point, geo.Point{Lat: area.CenterLat, Lng: area.CenterLng})
+		if outlet == "" || distance < nearest {
+			nearest, outlet = distance, area.Outlet
+		}
+	}
+	return nearest, outlet
+}
+
+func zoneName(zone *models.DeliveryZone) string {
+	name := zone.Area
+	if zone.District != "" {
+		name += "/" + zone.District
+	}
+	if zone.SubDistrict != "" {
+		name += "/" + zone.SubDistrict
+	}
+	return name
+}
+
+// ApplyDeliveryFee quotes the delivery fee of order for its address and
+// current price, and sets it on the order without saving.
+func ApplyDeliveryFee(tx *gorm.DB, order *models.Order) error {
+	address := order.Address
+	if address.ID == 0 {
+		if err := tx.First(&address, order.AddressID).Error; err != nil {
+			return err
+		}
+	}
+	subtotal := order.TotalPrice - order.Discount
+	if subtotal < 0 {
+		subtotal = 0
+	}
+	quote, err := QuoteDelivery(tx, address, subtotal)
+	if err != nil {
+		return err
+	}
+	order.DeliveryFee = quote.Fee
+	order.DeliveryFeeBasis = quote.Basis
+	return nil
+}
@@ -0,0 +1,153 @@
+package pricing
+
+import (
+	"testing"
+
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+)
+
+func TestQuoteDelivery(t *testing.T) {
+	lat, lng := -6.9750, 107.6300
+	withPoint := models.Address{Area: "Bojongsoang", District: "Lengkong", SubDistrict: "Cipagalo", Latitude: &lat, Longitude: &lng}
+	noPoint := models.Address{Area: "Bojongsoang", District: "Lengkong", SubDistrict: "Cipagalo"}
+
+	zones := []models.DeliveryZone{
+		{Area: "Bojongsoang", Fee: 10000},
+		{Area: "Bojongsoang", District: "Lengkong", SubDistrict: "Cipagalo", Fee: 5000, FreeAbove: 50000},
+		{Area: "Bojongsoang", District: "lengkong", Fee: 7000},
+		{Area: "Bojongsoang", District: "Bojongsari", Fee: 3000},
+	}
+	// due north of the address, 0.01 degree of latitude is about 1.112 km
+	outlets := []models.ServiceArea{
+		{Outlet: "Far", CenterLat: lat + 1, CenterLng: lng},
+		{Outlet: "Near", CenterLat: lat + 0.01, CenterLng: lng},
+	}
+
+	tests := []struct {
+		name     string
+		rates    deliveryRates
+		address  models.Address
+		subtotal float64
+		zones    []models.DeliveryZone
+		outlets  []models.ServiceArea
+		want     DeliveryQuote
+	}{
+		{
+			name:    "default fee",
+			rates:   deliveryRates{DefaultFee: 8000},
+			address: noPoint,
+			want:    DeliveryQuote{Fee: 8000, Basis: "default"},
+		},
+		{
+			name:    "area zone",
+			rates:   deliveryRates{DefaultFee: 8000},
+			address: models.Address{Area: "Bojongsoang", District: "Dayeuhkolot"},
+			zones:   zones,
+			want:    DeliveryQuote{Fee: 10000, Basis: "zone Bojongsoang"},
+		},
+		{
+			name:    "district zone beats area zone",
+			rates:   deliveryRates{DefaultFee: 8000},
+			address: models.Address{Area: "Bojongsoang", District: "Lengkong", SubDistrict: "Bojongsoang"},
+			zones:   zones,
+			want:    DeliveryQuote{Fee: 7000, Basis: "zone Bojongsoang/lengkong"},
+		},
+		{
+			name:    "sub-district zone beats district zone",
+			rates:   deliveryRates{DefaultFee: 8000},
+			address: noPoint,
+			zones:   zones,
+			want:    DeliveryQuote{Fee: 5000, Basis: "zone Bojongsoang/Lengkong/Cipagalo"},
+		},
+		{
+			name:     "zone threshold overrides the global one",
+			rates:    deliveryRates{DefaultFee: 8000, FreeAbove: 100000},
+			address:  noPoint,
+			subtotal: 60000,
+			zones:    zones,
+			want:     DeliveryQuote{Fee: 0, Basis: "free for orders from 50000"},
+		},
+		{
+			name:     "global threshold when the zone has none",
+			rates:    deliveryRates{DefaultFee: 8000, FreeAbove: 100000},
+			address:  models.Address{Area: "Bojongsoang", District: "Dayeuhkolot"},
+			subtotal: 60000,
+			zones:    zones,
+			want:     DeliveryQuote{Fee: 10000, Basis: "zone Bojongsoang"},
+		},
+		{
+			name:     "free exactly at the threshold",
+			rates:    deliveryRates{DefaultFee: 8000, FreeAbove: 100000},
+			address:  noPoint,
+			subtotal: 100000,
+			want:     DeliveryQuote{Fee: 0, Basis: "free for orders from 100000"},
+		},
+		{
+			name:     "just below the threshold",
+			rates:    deliveryRates{DefaultFee: 8000, FreeAbove: 100000},
+			address:  noPoint,
+			subtotal: 99999.99,
+			want:     DeliveryQuote{Fee: 8000, Basis: "default"},
+		},
+		{
+			name:    "per km beats the zone",
+			rates:   deliveryRates{DefaultFee: 8000, PerKm: 2000, BaseFee: 3000},
+			address: withPoint,
+			zones:   zones,
+			outlets: outlets,
+			want:    DeliveryQuote{Fee: 5223.9, Basis: "1.1 km from Near"},
+		},
+		{
+			name:    "per km without outlets falls back to the zone",
+			rates:   deliveryRates{DefaultFee: 8000, PerKm: 2000, BaseFee: 3000},
+			address: withPoint,
+			zones:   zones,
+			want:    DeliveryQuote{Fee: 5000, Basis: "zone Bojongsoang/Lengkong/Cipagalo"},
+		},
+		{
+			name:    "per km without coordinates falls back to the zone",
+			rates:   deliveryRates{DefaultFee: 8000, PerKm: 2000, BaseFee: 3000},
+			address: noPoint,
+			zones:   zones,
+			outlets: outlets,
+			want:    DeliveryQuote{Fee: 5000, Basis: "zone Bojongsoang/Lengkong/Cipagalo"},
+		},
+		{
+			name:    "outlets without a per km rate",
+			rates:   deliveryRates{DefaultFee: 8000},
+			address: withPoint,
+			outlets: outlets,
+			want:    DeliveryQuote{Fee: 8000, Basis: "default"},
+		},
+		{
+			name:     "free threshold beats per km",
+			rates:    deliveryRates{DefaultFee: 8000, PerKm: 2000, BaseFee: 3000},
+			address:  withPoint,
+			subtotal: 50000,
+			zones:    zones,
+			outlets:  outlets,
+			want:     DeliveryQuote{Fee: 0, Basis: "free for orders from 50000"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := quoteDelivery(tt.rates, tt.address, tt.subtotal, tt.zones, tt.outlets)
+			if got != tt.want {
+				t.Errorf("quoteDelivery() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeliveryRatesFromEnv(t *testing.T) {
+	t.Setenv("DELIVERY_DEFAULT_FEE", "8000")
+	t.Setenv("DELIVERY_FREE_ABOVE", "100000")
+	t.Setenv("DELIVERY_FEE_PER_KM", "-1")
+	t.Setenv("DELIVERY_BASE_FEE", "abc")
+
+	want := deliveryRates{DefaultFee: 8000, FreeAbove: 100000}
+	if got := deliveryRatesFromEnv(); got != want {
+		t.Errorf("deliveryRatesFromEnv() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,155 @@
+package pricing
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"gorm.io/gorm"
+)
+
+// BilledQuantity returns the quantity an order line is charged for: the
+// measured weight rounded by the service's rounding rule for kg, the measured
+// area for m², and the count for pieces and pairs.
+func BilledQuantity(item *models.OrderItem) float64 {
+	switch item.ServiceUnit {
+	case models.UnitKg:
+		return RoundWeight(item.Weight, item.ServiceWeightStep, item.ServiceWeightRounding)
+	case models.UnitSquareMeter:
+		return item.Area
+	default:
+		return float64(item.Quantity)
+	}
+}
+
+// RoundWeight rounds weight to a multiple of step. A zero step or the "none"
+// rule leaves the weight as measured.
+func RoundWeight(weight, step float64, rounding string) float64 {
+	if step <= 0 || rounding == "" || rounding == models.RoundingNone {
+		return weight
+	}
+	// Buang noise floating point sebelum membulatkan, 2.0000000001 tetap 2
+	units := math.Round(weight/step*1e6) / 1e6
+	switch rounding {
+	case models.RoundingUp:
+		units = math.Ceil(units)
+	case models.RoundingNearest:
+		units = math.Round(units)
+	}
+	return units * step
+}
+
+// CalculateItem builds the itemized price breakdown of one order line from
+// its service snapshot, measurement and chosen add-ons, and returns it
+// together with the line's subtotal.
+func CalculateItem(item *models.OrderItem) ([]models.OrderCharge, float64) {
+	quantity := BilledQuantity(item)
+
+	charges := []models.OrderCharge{{
+		Kind:        models.ChargeService,
+		Description: item.ServiceTitle,
+		Quantity:    quantity,
+		Unit:        item.ServiceUnit,
+		UnitPrice:   item.UnitPrice,
+		Amount:      money(item.UnitPrice * quantity),
+	}}
+
+	if shortfall := item.ServiceMinimumCharge - charges[0].Amount; shortfall > 0 {
+		charges = append(charges, models.OrderCharge{
+			Kind:        models.ChargeMinimum,
+			Description: fmt.Sprintf("Minimum charge %s (%s)", formatMoney(item.ServiceMinimumCharge), item.ServiceTitle),
+			Quantity:    1,
+			UnitPrice:   money(shortfall),
+			Amount:      money(shortfall),
+		})
+	}
+
+	for _, addon := range item.Addons {
+		charge := models.OrderCharge{
+			Kind:        models.ChargeAddon,
+			Description: addon.Name,
+			Quantity:    1,
+			UnitPrice:   addon.Price,
+			Amount:      money(addon.Price),
+		}
+		if addon.PerUnit {
+			charge.Quantity = quantity
+			charge.Unit = item.ServiceUnit
+			charge.Amount = money(addon.Price * quantity)
+		}
+		charges = append(charges, charge)
+	}
+
+	var subtotal float64
+	for _, charge := range charges {
+		subtotal += charge.Amount
+	}
+	return charges, money(subtotal)
+}
+
+// Calculate prices every line of an order, setting each line's subtotal, and
+// returns the combined breakdown and the order total.
+func Calculate(order *models.Order) ([]models.OrderCharge, float64) {
+	var charges []models.OrderCharge
+	var total float64
+	for i := range order.Items {
+		item := &order.Items[i]
+		itemCharges, subtotal := CalculateItem(item)
+		for j := range itemCharges {
+			if item.ID != 0 {
+				itemCharges[j].OrderItemID = &item.ID
+			}
+		}
+		item.Subtotal = subtotal
+		charges = append(charges, itemCharges...)
+		total += subtotal
+	}
+	return charges, money(total)
+}
+
+// Columns are the order fields Apply sets, for callers saving the order
+var Columns = []string{"total_price", "discount", "delivery_fee", "delivery_fee_basis"}
+
+// Apply prices the order and stores the breakdown and line subtotals,
+// replacing any previous breakdown, then recomputes the promo discount. The
+// order itself is not saved.
+func Apply(tx *gorm.DB, order *models.Order) error {
+	charges, total := Calculate(order)
+
+	for _, item := range order.Items {
+		if err := tx.Model(&item).Update("subtotal", item.Subtotal).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Unscoped().Where("order_id = ?", order.ID).Delete(&models.OrderCharge{}).Error; err != nil {
+		return err
+	}
+	for i := range charges {
+		charges[i].OrderID = order.ID
+	}
+	if len(charges) > 0 {
+		if err := tx.Create(&charges).Error; err != nil {
+			return err
+		}
+	}
+
+	order.Charges = charges
+	order.TotalPrice = total
+	if err := applyDiscount(tx, order); err != nil {
+		return err
+	}
+	return ApplyDeliveryFee(tx, order)
+}
+
+// money rounds an amount to whole cents to keep float sums stable
+func money(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+func formatMoney(amount float64) string {
+	if amount == math.Trunc(amount) {
+		return fmt.Sprintf("%.0f", amount)
+	}
+	return fmt.Sprintf("%.2f", amount)
+}
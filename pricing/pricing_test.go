@@ -0,0 +1,166 @@
+package pricing
+
+import (
+	"testing"
+
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+)
+
+func TestRoundWeight(t *testing.T) {
+	tests := []struct {
+		name     string
+		weight   float64
+		step     float64
+		rounding string
+		want     float64
+	}{
+		{"no step", 2.3, 0, models.RoundingUp, 2.3},
+		{"no rule", 2.3, 0.5, "", 2.3},
+		{"none rule", 2.3, 0.5, models.RoundingNone, 2.3},
+		{"up to half kg", 2.1, 0.5, models.RoundingUp, 2.5},
+		{"up exact multiple", 2.5, 0.5, models.RoundingUp, 2.5},
+		{"up float noise", 2.0000000001, 1, models.RoundingUp, 2},
+		{"up whole kg", 2.01, 1, models.RoundingUp, 3},
+		{"nearest down", 2.2, 0.5, models.RoundingNearest, 2},
+		{"nearest half rounds up", 2.25, 0.5, models.RoundingNearest, 2.5},
+		{"nearest up", 2.4, 0.5, models.RoundingNearest, 2.5},
+		{"zero weight", 0, 1, models.RoundingUp, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RoundWeight(tt.weight, tt.step, tt.rounding); got != tt.want {
+				t.Errorf("RoundWeight(%v, %v, %q) = %v, want %v", tt.weight, tt.step, tt.rounding, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBilledQuantity(t *testing.T) {
+	tests := []struct {
+		name string
+		item models.OrderItem
+		want float64
+	}{
+		{"kg rounded", models.OrderItem{ServiceUnit: models.UnitKg, Weight: 3.2, ServiceWeightStep: 1, ServiceWeightRounding: models.RoundingUp}, 4},
+		{"kg as measured", models.OrderItem{ServiceUnit: models.UnitKg, Weight: 3.2}, 3.2},
+		{"square meter", models.OrderItem{ServiceUnit: models.UnitSquareMeter, Area: 1.75, Quantity: 9}, 1.75},
+		{"pieces", models.OrderItem{ServiceUnit: models.UnitPiece, Quantity: 3, Weight: 9}, 3},
+		{"pairs", models.OrderItem{ServiceUnit: models.UnitPair, Quantity: 2}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BilledQuantity(&tt.item); got != tt.want {
+				t.Errorf("BilledQuantity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateItem(t *testing.T) {
+	tests := []struct {
+		name     string
+		item     models.OrderItem
+		kinds    []string
+		subtotal float64
+	}{
+		{
+			name:     "weight times price",
+			item:     models.OrderItem{ServiceTitle: "Cuci kering", ServiceUnit: models.UnitKg, UnitPrice: 7000, Weight: 2.5},
+			kinds:    []string{models.ChargeService},
+			subtotal: 17500,
+		},
+		{
+			name: "rounded weight",
+			item: models.OrderItem{ServiceTitle: "Cuci kering", ServiceUnit: models.UnitKg, UnitPrice: 7000, Weight: 2.1,
+				ServiceWeightStep: 1, ServiceWeightRounding: models.RoundingUp},
+			kinds:    []string{models.ChargeService},
+			subtotal: 21000,
+		},
+		{
+			name: "minimum charge tops up",
+			item: models.OrderItem{ServiceTitle: "Cuci kering", ServiceUnit: models.UnitKg, UnitPrice: 7000, Weight: 1,
+				ServiceMinimumCharge: 20000},
+			kinds:    []string{models.ChargeService, models.ChargeMinimum},
+			subtotal: 20000,
+		},
+		{
+			name: "minimum charge already reached",
+			item: models.OrderItem{ServiceTitle: "Cuci kering", ServiceUnit: models.UnitKg, UnitPrice: 7000, Weight: 3,
+				ServiceMinimumCharge: 20000},
+			kinds:    []string{models.ChargeService},
+			subtotal: 21000,
+		},
+		{
+			name: "minimum charge exactly reached",
+			item: models.OrderItem{ServiceTitle: "Sepatu", ServiceUnit: models.UnitPair, UnitPrice: 25000, Quantity: 2,
+				ServiceMinimumCharge: 50000},
+			kinds:    []string{models.ChargeService},
+			subtotal: 50000,
+		},
+		{
+			name: "flat and per unit add-ons",
+			item: models.OrderItem{ServiceTitle: "Cuci kering", ServiceUnit: models.UnitKg, UnitPrice: 7000, Weight: 2,
+				Addons: []models.OrderAddon{
+					{Name: "Express", Price: 10000},
+					{Name: "Parfum", Price: 1500, PerUnit: true},
+				}},
+			kinds:    []string{models.ChargeService, models.ChargeAddon, models.ChargeAddon},
+			subtotal: 27000,
+		},
+		{
+			name: "add-ons are not part of the minimum",
+			item: models.OrderItem{ServiceTitle: "Cuci kering", ServiceUnit: models.UnitKg, UnitPrice: 7000, Weight: 1,
+				ServiceMinimumCharge: 20000, Addons: []models.OrderAddon{{Name: "Express", Price: 10000}}},
+			kinds:    []string{models.ChargeService, models.ChargeMinimum, models.ChargeAddon},
+			subtotal: 30000,
+		},
+		{
+			name:     "cents are kept",
+			item:     models.OrderItem{ServiceTitle: "Karpet", ServiceUnit: models.UnitSquareMeter, UnitPrice: 12500.5, Area: 1.5},
+			kinds:    []string{models.ChargeService},
+			subtotal: 18750.75,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			charges, subtotal := CalculateItem(&tt.item)
+			if subtotal != tt.subtotal {
+				t.Errorf("subtotal = %v, want %v", subtotal, tt.subtotal)
+			}
+			if len(charges) != len(tt.kinds) {
+				t.Fatalf("got %d charges, want %d", len(charges), len(tt.kinds))
+			}
+			var sum float64
+			for i, charge := range charges {
+				if charge.Kind != tt.kinds[i] {
+					t.Errorf("charge %d kind = %q, want %q", i, charge.Kind, tt.kinds[i])
+				}
+				sum += charge.Amount
+			}
+			if money(sum) != subtotal {
+				t.Errorf("charges add up to %v, subtotal is %v", sum, subtotal)
+			}
+		})
+	}
+}
+
+func TestCalculate(t *testing.T) {
+	order := models.Order{Items: []models.OrderItem{
+		{ServiceTitle: "Cuci kering", ServiceUnit: models.UnitKg, UnitPrice: 7000, Weight: 1, ServiceMinimumCharge: 20000},
+		{ServiceTitle: "Sepatu", ServiceUnit: models.UnitPair, UnitPrice: 25000, Quantity: 2},
+	}}
+
+	charges, total := Calculate(&order)
+	if total != 70000 {
+		t.Errorf("total = %v, want 70000", total)
+	}
+	if len(charges) != 3 {
+		t.Errorf("got %d charges, want 3", len(charges))
+	}
+	if order.Items[0].Subtotal != 20000 || order.Items[1].Subtotal != 50000 {
+		t.Errorf("line subtotals = %v, %v, want 20000, 50000", order.Items[0].Subtotal, order.Items[1].Subtotal)
+	}
+}
@@ -0,0 +1,170 @@
+package pricing
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Promo errors carry messages that can be shown to the customer as is.
+var (
+	ErrPromoNotFound      = errors.New("promo code not found")
+	ErrPromoNotValid      = errors.New("promo code is not valid at this time")
+	ErrPromoUsedUp        = errors.New("promo code has reached its usage limit")
+	ErrPromoUserLimit     = errors.New("you have already used this promo code")
+	ErrPromoFirstOrder    = errors.New("promo code is only valid for your first order")
+	ErrPromoNotApplicable = errors.New("promo code does not apply to any service in this order")
+	ErrPromoAlreadyUsed   = errors.New("order already has a promo code")
+)
+
+// IsPromoError reports whether err is one of the promo errors above
+func IsPromoError(err error) bool {
+	for _, promoErr := range []error{ErrPromoNotFound, ErrPromoNotValid, ErrPromoUsedUp, ErrPromoUserLimit,
+		ErrPromoFirstOrder, ErrPromoNotApplicable, ErrPromoAlreadyUsed} {
+		if errors.Is(err, promoErr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Discount returns how much promo takes off the order. Only lines of the
+// promo's categories are discounted, and nothing is taken off before the
+// order reaches the promo's minimum value.
+func Discount(promo *models.Promo, order *models.Order) float64 {
+	if order.TotalPrice <= 0 || order.TotalPrice < promo.MinOrderValue {
+		return 0
+	}
+
+	var base float64
+	for _, item := range order.Items {
+		if promo.AppliesToCategory(item.ServiceCategory) {
+			base += item.Subtotal
+		}
+	}
+
+	var discount float64
+	switch promo.Type {
+	case models.PromoPercent:
+		discount = base * promo.Value / 100
+		if promo.MaxDiscount > 0 {
+			discount = math.Min(discount, promo.MaxDiscount)
+		}
+	case models.PromoFixed:
+		discount = promo.Value
+	}
+	return money(math.Max(0, math.Min(discount, base)))
+}
+
+// ApplyPromo attaches the promo with the given code to the order after
+// checking its validity window and usage limits, and records the redemption.
+// The order's promo fields are saved, the rest of the order is not.
+func ApplyPromo(tx *gorm.DB, order *models.Order, code string, now time.Time) error {
+	if order.PromoID != nil {
+		return ErrPromoAlreadyUsed
+	}
+
+	// Kunci baris promo agar batas pemakaian global tidak terlewati oleh request bersamaan
+	var promo models.Promo
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("code = ? AND active = ?", strings.ToUpper(strings.TrimSpace(code)), true).
+		First(&promo).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrPromoNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if !promo.ValidOn(now) {
+		return ErrPromoNotValid
+	}
+
+	applicable := false
+	for _, item := range order.Items {
+		applicable = applicable || promo.AppliesToCategory(item.ServiceCategory)
+	}
+	if !applicable {
+		return ErrPromoNotApplicable
+	}
+
+	if promo.UsageLimit > 0 {
+		var used int64
+		if err := tx.Model(&models.PromoRedemption{}).Where("promo_id = ?", promo.ID).Count(&used).Error; err != nil {
+			return err
+		}
+		if used >= int64(promo.UsageLimit) {
+			return ErrPromoUsedUp
+		}
+	}
+
+	if promo.PerUserLimit > 0 {
+		var usedByUser int64
+		if err := tx.Model(&models.PromoRedemption{}).Where("promo_id = ? AND user_id = ?", promo.ID, order.CustomerID).Count(&usedByUser).Error; err != nil {
+			return err
+		}
+		if usedByUser >= int64(promo.PerUserLimit) {
+			return ErrPromoUserLimit
+		}
+	}
+
+	// Order yang dibatalkan tidak dihitung sebagai order pertama
+	if promo.FirstOrderOnly {
+		var otherOrders int64
+		if err := tx.Model(&models.Order{}).Where("customer_id = ? AND id <> ? AND status <> ?", order.CustomerID, order.ID, models.OrderStatusCancelled).Count(&otherOrders).Error; err != nil {
+			return err
+		}
+		if otherOrders > 0 {
+			return ErrPromoFirstOrder
+		}
+	}
+
+	if err := tx.Create(&models.PromoRedemption{PromoID: promo.ID, UserID: order.CustomerID, OrderID: order.ID}).Error; err != nil {
+		return err
+	}
+
+	order.PromoID = &promo.ID
+	order.PromoCode = promo.Code
+	order.Discount = Discount(&promo, order)
+	return savePromoFields(tx, order)
+}
+
+// RemovePromo detaches the order's promo and frees its redemption.
+func RemovePromo(tx *gorm.DB, order *models.Order) error {
+	if err := tx.Where("order_id = ?", order.ID).Delete(&models.PromoRedemption{}).Error; err != nil {
+		return err
+	}
+	order.PromoID = nil
+	order.PromoCode = ""
+	order.Discount = 0
+	return savePromoFields(tx, order)
+}
+
+// applyDiscount recomputes the discount of an order that has a promo, it is
+// called whenever the order is priced.
+func applyDiscount(tx *gorm.DB, order *models.Order) error {
+	if order.PromoID == nil {
+		order.Discount = 0
+		return nil
+	}
+	var promo models.Promo
+	if err := tx.Unscoped().First(&promo, *order.PromoID).Error; err != nil {
+		return err
+	}
+	order.Discount = Discount(&promo, order)
+	return nil
+}
+
+// savePromoFields saves the promo of an order together with its delivery
+// fee, which may be waived depending on the discounted price
+func savePromoFields(tx *gorm.DB, order *models.Order) error {
+	if err := ApplyDeliveryFee(tx, order); err != nil {
+		return err
+	}
+	return tx.Model(order).Select("promo_id", "promo_code", "discount", "delivery_fee", "delivery_fee_basis").Updates(order).Error
+}
@@ -0,0 +1,61 @@
+package pricing
+
+import (
+	"testing"
+
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+)
+
+// orderLine is an order line of category with the given subtotal
+func orderLine(category string, subtotal float64) models.OrderItem {
+	return models.OrderItem{ServiceCategory: category, Subtotal: subtotal}
+}
+
+// pricedOrder is a priced order made of items
+func pricedOrder(items ...models.OrderItem) *models.Order {
+	o := models.Order{Items: items}
+	for _, item := range items {
+		o.TotalPrice += item.Subtotal
+	}
+	return &o
+}
+
+func TestDiscount(t *testing.T) {
+	tests := []struct {
+		name  string
+		promo models.Promo
+		order *models.Order
+		want  float64
+	}{
+		{"percent", models.Promo{Type: models.PromoPercent, Value: 10}, pricedOrder(orderLine("Kiloan", 50000)), 5000},
+		{"percent with cents", models.Promo{Type: models.PromoPercent, Value: 15}, pricedOrder(orderLine("Kiloan", 33333)), 4999.95},
+		{"percent under the cap", models.Promo{Type: models.PromoPercent, Value: 10, MaxDiscount: 10000}, pricedOrder(orderLine("Kiloan", 50000)), 5000},
+		{"percent capped", models.Promo{Type: models.PromoPercent, Value: 50, MaxDiscount: 10000}, pricedOrder(orderLine("Kiloan", 50000)), 10000},
+		{"percent above 100 capped at the subtotal", models.Promo{Type: models.PromoPercent, Value: 150}, pricedOrder(orderLine("Kiloan", 50000)), 50000},
+		{"fixed", models.Promo{Type: models.PromoFixed, Value: 15000}, pricedOrder(orderLine("Kiloan", 50000)), 15000},
+		{"fixed capped at the subtotal", models.Promo{Type: models.PromoFixed, Value: 75000}, pricedOrder(orderLine("Kiloan", 50000)), 50000},
+		{"fixed equal to the subtotal", models.Promo{Type: models.PromoFixed, Value: 50000}, pricedOrder(orderLine("Kiloan", 50000)), 50000},
+		{"negative value", models.Promo{Type: models.PromoFixed, Value: -5000}, pricedOrder(orderLine("Kiloan", 50000)), 0},
+		{"unknown type", models.Promo{Type: "bogo", Value: 5000}, pricedOrder(orderLine("Kiloan", 50000)), 0},
+		{"below minimum order", models.Promo{Type: models.PromoFixed, Value: 5000, MinOrderValue: 60000}, pricedOrder(orderLine("Kiloan", 50000)), 0},
+		{"exactly the minimum order", models.Promo{Type: models.PromoFixed, Value: 5000, MinOrderValue: 50000}, pricedOrder(orderLine("Kiloan", 50000)), 5000},
+		{"empty order", models.Promo{Type: models.PromoFixed, Value: 5000}, pricedOrder(), 0},
+		{"only matching categories", models.Promo{Type: models.PromoPercent, Value: 10, Categories: "Sepatu"},
+			pricedOrder(orderLine("Kiloan", 50000), orderLine("Sepatu", 30000)), 3000},
+		{"categories are case insensitive", models.Promo{Type: models.PromoPercent, Value: 10, Categories: "kiloan, sepatu"},
+			pricedOrder(orderLine("Kiloan", 50000), orderLine("Sepatu", 30000), orderLine("Karpet", 20000)), 8000},
+		{"fixed capped at the matching lines", models.Promo{Type: models.PromoFixed, Value: 40000, Categories: "Sepatu"},
+			pricedOrder(orderLine("Kiloan", 50000), orderLine("Sepatu", 30000)), 30000},
+		{"minimum checks the whole order", models.Promo{Type: models.PromoFixed, Value: 5000, Categories: "Sepatu", MinOrderValue: 60000},
+			pricedOrder(orderLine("Kiloan", 50000), orderLine("Sepatu", 30000)), 5000},
+		{"no matching category", models.Promo{Type: models.PromoFixed, Value: 5000, Categories: "Karpet"}, pricedOrder(orderLine("Kiloan", 50000)), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Discount(&tt.promo, tt.order); got != tt.want {
+				t.Errorf("Discount() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
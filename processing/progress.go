@@ -0,0 +1,93 @@
+package processing
+
+import (
+	"time"
+
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"gorm.io/gorm"
+)
+
+// Progress states of a stage for one order
+const (
+	StagePending    = "pending"
+	StageInProgress = "in_progress"
+	StageCompleted  = "completed"
+)
+
+// StageProgress is where an order stands in one stage
+type StageProgress struct {
+	Code        string
+	Name        string
+	State       string
+	StartedAt   *time.Time
+	CompletedAt *time.Time
+}
+
+// Progress lays the stage events of an order over the active stages. A stage
+// visited more than once shows its latest visit.
+func Progress(stages []models.ProcessingStage, events []models.OrderStageEvent) []StageProgress {
+	latest := map[string]models.OrderStageEvent{}
+	for _, event := range events {
+		if current, ok := latest[event.Stage]; !ok || !event.StartedAt.Before(current.StartedAt) {
+			latest[event.Stage] = event
+		}
+	}
+
+	progress := make([]StageProgress, 0, len(stages))
+	for _, stage := range stages {
+		entry := StageProgress{Code: stage.Code, Name: stage.Name, State: StagePending}
+		if event, ok := latest[stage.Code]; ok {
+			startedAt := event.StartedAt
+			entry.StartedAt = &startedAt
+			entry.CompletedAt = event.CompletedAt
+			entry.State = StageInProgress
+			if event.CompletedAt != nil {
+				entry.State = StageCompleted
+			}
+		}
+		progress = append(progress, entry)
+	}
+	return progress
+}
+
+// StageCount is the number of orders in progress sitting in a stage. Orders
+// the outlet has not started on yet are counted under an empty Code.
+type StageCount struct {
+	Code   string `json:"code"`
+	Name   string `json:"name"`
+	Orders int64  `json:"orders"`
+}
+
+// Summary counts the orders in progress per stage
+func Summary(db *gorm.DB) ([]StageCount, error) {
+	var rows []struct {
+		ProcessingStage string
+		Orders          int64
+	}
+	err := db.Model(&models.Order{}).Select("processing_stage, COUNT(*) AS orders").
+		Where("status = ?", models.OrderStatusInProgress).
+		Group("processing_stage").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	stages, err := Stages(db)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int64{}
+	for _, row := range rows {
+		counts[row.ProcessingStage] = row.Orders
+	}
+	summary := []StageCount{{Code: "", Name: "Not started", Orders: counts[""]}}
+	delete(counts, "")
+	for _, stage := range stages {
+		summary = append(summary, StageCount{Code: stage.Code, Name: stage.Name, Orders: counts[stage.Code]})
+		delete(counts, stage.Code)
+	}
+	// Tahap yang sudah dinonaktifkan tetap dihitung selama masih ada order di sana
+	for code, orders := range counts {
+		summary = append(summary, StageCount{Code: code, Name: code, Orders: orders})
+	}
+	return summary, nil
+}
@@ -0,0 +1,141 @@
+package processing
+
+import (
+	"errors"
+	"time"
+
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrNotInProgress is returned for orders the outlet is not working on
+	ErrNotInProgress = errors.New("order is not in progress")
+	// ErrNoStages is returned when no processing stage is active
+	ErrNoStages = errors.New("no processing stages are configured")
+	// ErrUnknownStage is returned for a stage that is not active
+	ErrUnknownStage = errors.New("unknown processing stage")
+	// ErrSameStage is returned when moving an order to the stage it is in
+	ErrSameStage = errors.New("order is already at this processing stage")
+	// ErrLastStage is returned when advancing an order past the last stage;
+	// it is finished by marking the order done
+	ErrLastStage = errors.New("order is already at the last processing stage, mark it as done instead")
+	// ErrStageChanged is returned when someone else moved the order first
+	ErrStageChanged = errors.New("order stage was changed by someone else, please reload")
+)
+
+// IsStageError reports whether err is caused by the request rather than by
+// the database
+func IsStageError(err error) bool {
+	return errors.Is(err, ErrNotInProgress) || errors.Is(err, ErrNoStages) || errors.Is(err, ErrUnknownStage) ||
+		errors.Is(err, ErrSameStage) || errors.Is(err, ErrLastStage) || errors.Is(err, ErrStageChanged)
+}
+
+// DefaultStages are created on first start so outlets can begin right away
+var DefaultStages = []models.ProcessingStage{
+	{Code: "sorting", Name: "Sorting", Position: 1, Active: true},
+	{Code: "washing", Name: "Washing", Position: 2, Active: true},
+	{Code: "drying", Name: "Drying", Position: 3, Active: true},
+	{Code: "ironing", Name: "Ironing", Position: 4, Active: true},
+	{Code: "packing", Name: "Packing", Position: 5, Active: true},
+	{Code: "quality_check", Name: "Quality check", Position: 6, Active: true},
+}
+
+// EnsureDefaults creates DefaultStages when no stage was ever configured
+func EnsureDefaults(db *gorm.DB) error {
+	var count int64
+	if err := db.Unscoped().Model(&models.ProcessingStage{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	stages := make([]models.ProcessingStage, len(DefaultStages))
+	copy(stages, DefaultStages)
+	return db.Create(&stages).Error
+}
+
+// Stages returns the active stages in the order they run
+func Stages(db *gorm.DB) ([]models.ProcessingStage, error) {
+	var stages []models.ProcessingStage
+	err := db.Where("active = ?", true).Order("position, id").Find(&stages).Error
+	return stages, err
+}
+
+// Advance moves an order in progress to the stage with code to, or to the
+// stage after its current one when to is empty. Going back, e.g. when the
+// quality check fails, is allowed. The stage the order leaves is completed.
+func Advance(db *gorm.DB, order *models.Order, to string, actorID uint) (models.OrderStageEvent, error) {
+	var event models.OrderStageEvent
+	if order.Status != models.OrderStatusInProgress {
+		return event, ErrNotInProgress
+	}
+	stages, err := Stages(db)
+	if err != nil {
+		return event, err
+	}
+	if len(stages) == 0 {
+		return event, ErrNoStages
+	}
+
+	target := ""
+	if to == "" {
+		// Order yang belum mulai, atau yang tahapnya sudah dinonaktifkan, mulai dari tahap pertama
+		target = stages[0].Code
+		for i, stage := range stages {
+			if stage.Code != order.ProcessingStage {
+				continue
+			}
+			if i == len(stages)-1 {
+				return event, ErrLastStage
+			}
+			target = stages[i+1].Code
+		}
+	} else {
+		for _, stage := range stages {
+			if stage.Code == to {
+				target = to
+			}
+		}
+		if target == "" {
+			return event, ErrUnknownStage
+		}
+		if target == order.ProcessingStage {
+			return event, ErrSameStage
+		}
+	}
+
+	now := time.Now()
+	event = models.OrderStageEvent{OrderID: order.ID, Stage: target, StartedAt: now}
+	if actorID != 0 {
+		event.StartedByID = &actorID
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Order{}).
+			Where("id = ? AND status = ? AND processing_stage = ?", order.ID, models.OrderStatusInProgress, order.ProcessingStage).
+			Update("processing_stage", target)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrStageChanged
+		}
+		if err := Finish(tx, order.ID, now); err != nil {
+			return err
+		}
+		return tx.Create(&event).Error
+	})
+	if err != nil {
+		return event, err
+	}
+	order.ProcessingStage = target
+	return event, nil
+}
+
+// Finish completes the stage an order is in, for example when it is marked
+// done
+func Finish(db *gorm.DB, orderID uint, at time.Time) error {
+	return db.Model(&models.OrderStageEvent{}).
+		Where("order_id = ? AND completed_at IS NULL", orderID).
+		Update("completed_at", at).Error
+}
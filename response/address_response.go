@@ -1,17 +1,43 @@
 package response
 
+import (
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+)
+
 // AddressResponse represents address data without timestamps
 type AddressResponse struct {
-	ID            uint   `json:"id"`
-	CustomerID    uint   `json:"customer_id"`
-	ReceiverName  string `json:"receiver_name"`
-	PhoneNumber   string `json:"phone_number"`
-	HouseNumber   string `json:"house_number"`
-	ResidenceName string `json:"residence_name"`
-	AddressNotes  string `json:"address_notes"`
-	StreetName    string `json:"street_name"`
-	District      string `json:"district"`
-	SubDistrict   string `json:"sub_district"`
-	City          string `json:"city"`
-	Area          string `json:"area"`
+	ID            uint     `json:"id"`
+	CustomerID    uint     `json:"customer_id"`
+	ReceiverName  string   `json:"receiver_name"`
+	PhoneNumber   string   `json:"phone_number"`
+	HouseNumber   string   `json:"house_number"`
+	ResidenceName string   `json:"residence_name"`
+	AddressNotes  string   `json:"address_notes"`
+	StreetName    string   `json:"street_name"`
+	District      string   `json:"district"`
+	SubDistrict   string   `json:"sub_district"`
+	City          string   `json:"city"`
+	Area          string   `json:"area"`
+	Latitude      *float64 `json:"latitude,omitempty"`
+	Longitude     *float64 `json:"longitude,omitempty"`
+}
+
+// NewAddress converts an address to its response
+func NewAddress(address models.Address) AddressResponse {
+	return AddressResponse{
+		ID:            address.ID,
+		CustomerID:    address.CustomerID,
+		ReceiverName:  address.ReceiverName,
+		PhoneNumber:   address.PhoneNumber,
+		HouseNumber:   address.HouseNumber,
+		ResidenceName: address.ResidenceName,
+		AddressNotes:  address.AddressNotes,
+		StreetName:    address.StreetName,
+		District:      address.District,
+		SubDistrict:   address.SubDistrict,
+		City:          address.City,
+		Area:          address.Area,
+		Latitude:      address.Latitude,
+		Longitude:     address.Longitude,
+	}
 }
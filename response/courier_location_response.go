@@ -0,0 +1,17 @@
+package response
+
+import (
+	"time"
+)
+
+// CourierLocationResponse is where the courier of an order was last seen and
+// how long they need to the order's address in a straight line
+type CourierLocationResponse struct {
+	OrderID    uint      `json:"order_id"`
+	Status     string    `json:"status"`
+	Latitude   float64   `json:"latitude"`
+	Longitude  float64   `json:"longitude"`
+	RecordedAt time.Time `json:"recorded_at"`
+	DistanceKm *float64  `json:"distance_km,omitempty"`
+	ETAMinutes *int      `json:"eta_minutes,omitempty"`
+}
@@ -0,0 +1,45 @@
+package response
+
+import (
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+)
+
+// GarmentResponse is a tagged garment of an order and where it was last seen
+type GarmentResponse struct {
+	ID            uint   `json:"id"`
+	OrderID       uint   `json:"order_id"`
+	Tag           string `json:"tag"`
+	Type          string `json:"type"`
+	Colour        string `json:"colour,omitempty"`
+	Brand         string `json:"brand,omitempty"`
+	Condition     string `json:"condition,omitempty"`
+	Notes         string `json:"notes,omitempty"`
+	LastStage     string `json:"last_stage"`
+	LastScannedAt string `json:"last_scanned_at,omitempty"`
+}
+
+func NewGarment(garment models.Garment) GarmentResponse {
+	result := GarmentResponse{
+		ID:        garment.ID,
+		OrderID:   garment.OrderID,
+		Tag:       garment.Tag,
+		Type:      garment.Type,
+		Colour:    garment.Colour,
+		Brand:     garment.Brand,
+		Condition: garment.Condition,
+		Notes:     garment.Notes,
+		LastStage: garment.LastStage,
+	}
+	if garment.LastScannedAt != nil {
+		result.LastScannedAt = garment.LastScannedAt.Format("2006-01-02 15:04:05")
+	}
+	return result
+}
+
+func NewGarments(garments []models.Garment) []GarmentResponse {
+	result := []GarmentResponse{}
+	for _, garment := range garments {
+		result = append(result, NewGarment(garment))
+	}
+	return result
+}
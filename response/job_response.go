@@ -0,0 +1,15 @@
+package response
+
+import (
+	"time"
+)
+
+// JobResponse is an order waiting for a courier on the job board
+type JobResponse struct {
+	OrderID        uint              `json:"order_id"`
+	CreatedAt      time.Time         `json:"created_at"`
+	WaitingMinutes int               `json:"waiting_minutes"`
+	Services       []string          `json:"services"`
+	PickupSlot     *TimeSlotResponse `json:"pickup_slot,omitempty"`
+	Address        AddressResponse   `json:"address"`
+}
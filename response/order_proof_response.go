@@ -0,0 +1,37 @@
+package response
+
+import (
+	"fmt"
+
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+)
+
+// OrderProofResponse is a pickup or delivery proof. URL downloads the file
+// for the parties of the order.
+type OrderProofResponse struct {
+	ID            uint   `json:"id"`
+	Stage         string `json:"stage"`
+	Kind          string `json:"kind"`
+	ContentType   string `json:"content_type"`
+	Size          int64  `json:"size"`
+	RecipientName string `json:"recipient_name,omitempty"`
+	URL           string `json:"url"`
+	CreatedAt     string `json:"created_at"`
+}
+
+func NewOrderProofs(proofs []models.OrderProof) []OrderProofResponse {
+	result := []OrderProofResponse{}
+	for _, proof := range proofs {
+		result = append(result, OrderProofResponse{
+			ID:            proof.ID,
+			Stage:         proof.Stage,
+			Kind:          proof.Kind,
+			ContentType:   proof.ContentType,
+			Size:          proof.Size,
+			RecipientName: proof.RecipientName,
+			URL:           fmt.Sprintf("/api/orders/%d/proofs/%d/file", proof.OrderID, proof.ID),
+			CreatedAt:     proof.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+	return result
+}
@@ -1,16 +1,120 @@
 package response
 
+import (
+	"time"
+
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+)
+
 type OrderResponse struct {
-	ID         uint            `json:"id"`
-	Status     string          `json:"status"`
-	CreatedAt  string          `json:"created_at"`
-	UpdatedAt  string          `json:"updated_at"`
-	TotalPrice float64         `json:"total_price,omitempty"`
-	Weight     float64         `json:"weight,omitempty"`
-	Quantity   int             `json:"quantity,omitempty"` // Menambahkan field Quantity
-	Customer   UserResponse    `json:"customer"`
-	Courier    UserResponse    `json:"courier"`
-	Admin      UserResponse    `json:"admin"`
-	Service    ServiceResponse `json:"service"`
-	Address    AddressResponse `json:"address"`
+	ID                  uint                    `json:"id"`
+	Status              string                  `json:"status"`
+	CreatedAt           string                  `json:"created_at"`
+	UpdatedAt           string                  `json:"updated_at"`
+	TotalPrice          float64                 `json:"total_price,omitempty"`
+	PromoCode           string                  `json:"promo_code,omitempty"`
+	Discount            float64                 `json:"discount,omitempty"`
+	AmountDue           float64                 `json:"amount_due,omitempty"`
+	NetPaid             float64                 `json:"net_paid"`
+	DeliveryFee         float64                 `json:"delivery_fee"`
+	DeliveryFeeBasis    string                  `json:"delivery_fee_basis"`
+	DeliveryCode        string                  `json:"delivery_code,omitempty"`
+	ProcessingStage     string                  `json:"processing_stage,omitempty"`
+	Progress            []StageProgressResponse `json:"progress,omitempty"`
+	Weight              float64                 `json:"weight,omitempty"`
+	Quantity            int                     `json:"quantity,omitempty"` // Menambahkan field Quantity
+	Area                float64                 `json:"area,omitempty"`
+	Items               []OrderItemResponse     `json:"items,omitempty"`
+	Addons              []OrderAddonResponse    `json:"addons,omitempty"`
+	PriceBreakdown      []OrderChargeResponse   `json:"price_breakdown,omitempty"`
+	PickupSlot          *TimeSlotResponse       `json:"pickup_slot,omitempty"`
+	DeliverySlot        *TimeSlotResponse       `json:"delivery_slot,omitempty"`
+	EstimatedReadyAt    *time.Time              `json:"estimated_ready_at,omitempty"`
+	EstimatedDeliveryAt *time.Time              `json:"estimated_delivery_at,omitempty"`
+	Customer            UserResponse            `json:"customer"`
+	Courier             UserResponse            `json:"courier"`
+	Admin               UserResponse            `json:"admin"`
+	Service             ServiceResponse         `json:"service"`
+	Address             AddressResponse         `json:"address"`
+}
+
+// OrderItemResponse is one service line of an order
+type OrderItemResponse struct {
+	ID        uint                 `json:"id"`
+	ServiceID uint                 `json:"service_id"`
+	Title     string               `json:"title"`
+	Unit      string               `json:"unit"`
+	UnitPrice float64              `json:"unit_price"`
+	Weight    float64              `json:"weight,omitempty"`
+	Quantity  int                  `json:"quantity,omitempty"`
+	Area      float64              `json:"area,omitempty"`
+	Subtotal  float64              `json:"subtotal"`
+	Addons    []OrderAddonResponse `json:"addons,omitempty"`
+}
+
+type OrderAddonResponse struct {
+	Code    string  `json:"code"`
+	Name    string  `json:"name"`
+	Price   float64 `json:"price"`
+	PerUnit bool    `json:"per_unit"`
+}
+
+// OrderChargeResponse is one line of an order's price breakdown
+type OrderChargeResponse struct {
+	Kind        string  `json:"kind"`
+	Description string  `json:"description"`
+	Quantity    float64 `json:"quantity"`
+	Unit        string  `json:"unit,omitempty"`
+	UnitPrice   float64 `json:"unit_price"`
+	Amount      float64 `json:"amount"`
+}
+
+// NewOrderItems converts the lines of an order
+func NewOrderItems(items []models.OrderItem) []OrderItemResponse {
+	var itemResponses []OrderItemResponse
+	for _, item := range items {
+		itemResponses = append(itemResponses, OrderItemResponse{
+			ID:        item.ID,
+			ServiceID: item.ServiceID,
+			Title:     item.ServiceTitle,
+			Unit:      item.ServiceUnit,
+			UnitPrice: item.UnitPrice,
+			Weight:    item.Weight,
+			Quantity:  item.Quantity,
+			Area:      item.Area,
+			Subtotal:  item.Subtotal,
+			Addons:    NewOrderAddons(item.Addons),
+		})
+	}
+	return itemResponses
+}
+
+// NewOrderAddons converts the add-ons chosen for an order
+func NewOrderAddons(addons []models.OrderAddon) []OrderAddonResponse {
+	var addonResponses []OrderAddonResponse
+	for _, addon := range addons {
+		addonResponses = append(addonResponses, OrderAddonResponse{
+			Code:    addon.Code,
+			Name:    addon.Name,
+			Price:   addon.Price,
+			PerUnit: addon.PerUnit,
+		})
+	}
+	return addonResponses
+}
+
+// NewPriceBreakdown converts the stored charges of an order
+func NewPriceBreakdown(charges []models.OrderCharge) []OrderChargeResponse {
+	var chargeResponses []OrderChargeResponse
+	for _, charge := range charges {
+		chargeResponses = append(chargeResponses, OrderChargeResponse{
+			Kind:        charge.Kind,
+			Description: charge.Description,
+			Quantity:    charge.Quantity,
+			Unit:        charge.Unit,
+			UnitPrice:   charge.UnitPrice,
+			Amount:      charge.Amount,
+		})
+	}
+	return chargeResponses
 }
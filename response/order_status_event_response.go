@@ -0,0 +1,12 @@
+package response
+
+type OrderStatusEventResponse struct {
+	ID         uint                 `json:"id"`
+	FromStatus string               `json:"from_status"`
+	ToStatus   string               `json:"to_status"`
+	ActorRole  string               `json:"actor_role"`
+	Note       string               `json:"note,omitempty"`
+	CreatedAt  string               `json:"created_at"`
+	Actor      UserResponse         `json:"actor"`
+	Proofs     []OrderProofResponse `json:"proofs,omitempty"`
+}
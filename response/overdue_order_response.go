@@ -0,0 +1,18 @@
+package response
+
+import (
+	"time"
+)
+
+// OverdueOrderResponse is an order that missed its estimated ready or
+// delivery time
+type OverdueOrderResponse struct {
+	OrderID             uint         `json:"order_id"`
+	Status              string       `json:"status"`
+	Breach              string       `json:"breach"` // "ready" or "delivery"
+	EstimatedReadyAt    *time.Time   `json:"estimated_ready_at"`
+	EstimatedDeliveryAt *time.Time   `json:"estimated_delivery_at"`
+	OverdueMinutes      int          `json:"overdue_minutes"`
+	Customer            UserResponse `json:"customer"`
+	Courier             UserResponse `json:"courier"`
+}
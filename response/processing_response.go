@@ -0,0 +1,30 @@
+package response
+
+import (
+	"time"
+
+	"github.com/raihansyahrin/backend_laundry_app.git/processing"
+)
+
+// StageProgressResponse is where an order stands in one processing stage
+type StageProgressResponse struct {
+	Code        string     `json:"code"`
+	Name        string     `json:"name"`
+	State       string     `json:"state"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+func NewStageProgress(progress []processing.StageProgress) []StageProgressResponse {
+	result := []StageProgressResponse{}
+	for _, stage := range progress {
+		result = append(result, StageProgressResponse{
+			Code:        stage.Code,
+			Name:        stage.Name,
+			State:       stage.State,
+			StartedAt:   stage.StartedAt,
+			CompletedAt: stage.CompletedAt,
+		})
+	}
+	return result
+}
@@ -5,4 +5,5 @@ type ServiceResponse struct {
 	ID    uint   `json:"id"`
 	Title string `json:"title"`
 	Price uint   `json:"price"`
+	Unit  string `json:"unit,omitempty"`
 }
@@ -0,0 +1,41 @@
+package response
+
+import (
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+)
+
+// TimeSlotResponse is a pickup or delivery window
+type TimeSlotResponse struct {
+	ID        uint   `json:"id"`
+	Kind      string `json:"kind"`
+	Date      string `json:"date"`
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+	Area      string `json:"area,omitempty"`
+	Remaining int    `json:"remaining"`
+}
+
+// NewTimeSlot returns nil for an order without a slot
+func NewTimeSlot(slot models.TimeSlot) *TimeSlotResponse {
+	if slot.ID == 0 {
+		return nil
+	}
+	return &TimeSlotResponse{
+		ID:        slot.ID,
+		Kind:      slot.Kind,
+		Date:      slot.Date,
+		StartTime: slot.StartTime,
+		EndTime:   slot.EndTime,
+		Area:      slot.Area,
+		Remaining: slot.Remaining(),
+	}
+}
+
+// ScheduleEntryResponse is a pickup or delivery a courier has to make
+type ScheduleEntryResponse struct {
+	OrderID uint              `json:"order_id"`
+	Status  string            `json:"status"`
+	Kind    string            `json:"kind"`
+	Slot    *TimeSlotResponse `json:"slot"`
+	Address AddressResponse   `json:"address"`
+}
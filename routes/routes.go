@@ -14,30 +14,41 @@ func SetupRoutes(router *gin.Engine) {
 	{
 		authRoutes.POST("/register", controllers.Register)
 		authRoutes.POST("/login", controllers.Login)
+		authRoutes.POST("/refresh", controllers.RefreshToken)
+		authRoutes.POST("/invites/redeem", controllers.RedeemInvite)
+		authRoutes.POST("/logout", middlewares.AuthMiddleware(), controllers.Logout)
+		authRoutes.POST("/logout-all", middlewares.AuthMiddleware(), controllers.LogoutAll)
 	}
 
 	userRoutes := router.Group("api/users")
 	{
-		userRoutes.GET("/", middlewares.AuthMiddleware(), controllers.GetUsers)
-		userRoutes.GET("/:id", middlewares.AuthMiddleware(), controllers.GetUser)
-		userRoutes.PUT("/:id", middlewares.AuthMiddleware(), controllers.UpdateUser)
-		userRoutes.DELETE("/:id", middlewares.AuthMiddleware(), controllers.DeleteUser)
+		self := middlewares.OwnerOrAdmin(middlewares.User(middlewares.Param("id")))
+		userRoutes.GET("/", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), controllers.GetUsers)
+		userRoutes.GET("/:id", middlewares.AuthMiddleware(), self, controllers.GetUser)
+		userRoutes.PUT("/:id", middlewares.AuthMiddleware(), self, controllers.UpdateUser)
+		userRoutes.DELETE("/:id", middlewares.AuthMiddleware(), self, controllers.DeleteUser)
 	}
 
 	customerGroup := router.Group("api/customers")
 	{
-		customerGroup.GET("/", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("customer"), customer_controller.GetCustomers)
-		customerGroup.GET("/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("customer"), customer_controller.GetCustomer)
-		customerGroup.PUT("/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("customer"), customer_controller.UpdateCustomer)
-		customerGroup.DELETE("/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("customer"), customer_controller.DeleteCustomer)
+		self := middlewares.OwnerOrAdmin(middlewares.User(middlewares.Param("id")))
+		customerGroup.GET("/", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), customer_controller.GetCustomers)
+		customerGroup.GET("/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("customer", "admin"), self, customer_controller.GetCustomer)
+		customerGroup.PUT("/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("customer", "admin"), self, customer_controller.UpdateCustomer)
+		customerGroup.DELETE("/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("customer", "admin"), self, customer_controller.DeleteCustomer)
+		customerGroup.GET("/:id/orders", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("customer", "admin"), self, customer_controller.GetOrderDetailForCustomer)
 	}
 
 	courierGroup := router.Group("api/couriers")
 	{
-		courierGroup.GET("/", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("couriers"), courier_controllers.GetCouriers)
-		courierGroup.GET("/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("couriers"), courier_controllers.GetCourier)
-		courierGroup.PUT("/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("couriers"), courier_controllers.UpdateCourier)
-		courierGroup.DELETE("/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("couriers"), courier_controllers.DeleteCourier)
+		self := middlewares.OwnerOrAdmin(middlewares.User(middlewares.Param("id")))
+		courierGroup.GET("/", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), courier_controllers.GetCouriers)
+		courierGroup.GET("/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("courier", "admin"), self, courier_controllers.GetCourier)
+		courierGroup.PUT("/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("courier", "admin"), self, courier_controllers.UpdateCourier)
+		courierGroup.DELETE("/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("courier", "admin"), self, courier_controllers.DeleteCourier)
+		courierGroup.POST("/location", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("courier"), courier_controllers.UpdateLocation)
+		courierGroup.GET("/:id/areas", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("courier", "admin"), self, courier_controllers.GetCourierAreas)
+		courierGroup.PUT("/:id/areas", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), courier_controllers.SetCourierAreas)
 	}
 
 	adminGroup := router.Group("api/admins")
@@ -46,27 +57,91 @@ func SetupRoutes(router *gin.Engine) {
 		adminGroup.GET("/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), admin_controllers.GetAdmin)
 		adminGroup.PUT("/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), admin_controllers.UpdateAdmin)
 		adminGroup.DELETE("/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), admin_controllers.DeleteAdmin)
+		adminGroup.POST("/staff", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), admin_controllers.CreateStaff)
+		adminGroup.POST("/invites", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), admin_controllers.InviteStaff)
 	}
 
 	orderRoutes := router.Group("api/orders")
 	{
-		orderRoutes.GET("/", middlewares.AuthMiddleware(), controllers.GetOrders)
-		orderRoutes.PUT("/status", middlewares.AuthMiddleware(), controllers.UpdateOrderStatus)
-		orderRoutes.DELETE("/", middlewares.AuthMiddleware(), controllers.DeleteOrder)
-		orderRoutes.POST("/payment", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("customer", "courier"), customer_controller.ProcessPayment)
+		// Policy per order: customer pemilik order, kurir yang ditugaskan, atau admin
+		bodyOrder := middlewares.BodyField("order_id")
+		orderParties := middlewares.Authorize(
+			middlewares.Roles("admin"),
+			middlewares.Owner(middlewares.OrderCustomer(bodyOrder)),
+			middlewares.Owner(middlewares.OrderCourier(bodyOrder)),
+		)
+		assignedCourier := middlewares.Authorize(middlewares.Owner(middlewares.OrderCourier(bodyOrder)))
+
+		orderRoutes.GET("/", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), controllers.GetOrders)
+		orderRoutes.PUT("/status", middlewares.AuthMiddleware(), orderParties, controllers.UpdateOrderStatus)
+		orderRoutes.DELETE("/", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), controllers.DeleteOrder)
+		orderRoutes.POST("/:id/cancel", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("customer", "admin"), middlewares.OwnerOrAdmin(middlewares.OrderCustomer(middlewares.Param("id"))), controllers.CancelOrder)
+		orderRoutes.GET("/:id/history", middlewares.AuthMiddleware(), middlewares.Authorize(
+			middlewares.Roles("admin"),
+			middlewares.Owner(middlewares.OrderCustomer(middlewares.Param("id"))),
+			middlewares.Owner(middlewares.OrderCourier(middlewares.Param("id"))),
+		), controllers.GetOrderHistory)
+		orderRoutes.GET("/:id/proofs/:proof_id/file", middlewares.AuthMiddleware(), middlewares.Authorize(
+			middlewares.Roles("admin"),
+			middlewares.Owner(middlewares.OrderCustomer(middlewares.Param("id"))),
+			middlewares.Owner(middlewares.OrderCourier(middlewares.Param("id"))),
+		), controllers.GetOrderProofFile)
+		orderRoutes.GET("/:id/garments", middlewares.AuthMiddleware(), middlewares.Authorize(
+			middlewares.Roles("admin"),
+			middlewares.Owner(middlewares.OrderCustomer(middlewares.Param("id"))),
+			middlewares.Owner(middlewares.OrderCourier(middlewares.Param("id"))),
+		), controllers.GetGarments)
+		// Tag dicatat kurir saat penjemputan atau outlet saat cucian diterima
+		garmentHandlers := middlewares.Authorize(
+			middlewares.Roles("admin"),
+			middlewares.Owner(middlewares.OrderCourier(middlewares.Param("id"))),
+		)
+		orderRoutes.POST("/:id/garments", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("courier", "admin"), garmentHandlers, controllers.RegisterGarments)
+		orderRoutes.POST("/:id/garments/scan", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("courier", "admin"), garmentHandlers, controllers.ScanGarment)
+		orderRoutes.GET("/:id/garments/report", middlewares.AuthMiddleware(), middlewares.OwnerOrAdmin(middlewares.OrderCustomer(middlewares.Param("id"))), controllers.GetGarmentReport)
+		orderRoutes.POST("/payment", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("customer", "courier"), orderParties, customer_controller.ProcessPayment)
 
 		//Customer
 		orderRoutes.POST("/", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("customer"), customer_controller.CreateOrder)
-		orderRoutes.GET("/:customer_id", middlewares.AuthMiddleware(), customer_controller.GetOrderDetailForCustomer)
+		// Deprecated: :id di sini adalah customer ID, gunakan GET /api/customers/:id/orders
+		orderRoutes.GET("/:id", middlewares.AuthMiddleware(), middlewares.Deprecated(func(c *gin.Context) string {
+			return "/api/customers/" + c.Param("id") + "/orders"
+		}), middlewares.OwnerOrAdmin(middlewares.User(middlewares.Param("id"))), customer_controller.GetOrderDetailForCustomer)
+		orderRoutes.POST("/:id/promo", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("customer"), middlewares.Authorize(middlewares.Owner(middlewares.OrderCustomer(middlewares.Param("id")))), customer_controller.ApplyPromo)
+		orderRoutes.DELETE("/:id/promo", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("customer"), middlewares.Authorize(middlewares.Owner(middlewares.OrderCustomer(middlewares.Param("id")))), customer_controller.RemovePromo)
+
+		orderRoutes.GET("/:id/courier-location", middlewares.AuthMiddleware(), middlewares.OwnerOrAdmin(middlewares.OrderCustomer(middlewares.Param("id"))), customer_controller.GetCourierLocation)
+		orderRoutes.GET("/:id/refunds", middlewares.AuthMiddleware(), middlewares.Authorize(
+			middlewares.Roles("admin"),
+			middlewares.Owner(middlewares.OrderCustomer(middlewares.Param("id"))),
+		), admin_controllers.GetRefunds)
 
 		//Courier
+		orderRoutes.GET("/jobs", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("courier"), courier_controllers.GetJobBoard)
+		orderRoutes.GET("/schedule", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("courier"), courier_controllers.GetCourierSchedule)
 		orderRoutes.POST("/accept/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("courier"), courier_controllers.AcceptOrder)
-		orderRoutes.POST("/courier-arrived", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("courier"), courier_controllers.CourierArrived)
-		orderRoutes.POST("/accept-cash-payment", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("courier"), courier_controllers.AcceptCashPayment)
+		orderRoutes.POST("/courier-arrived", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("courier"), assignedCourier, courier_controllers.CourierArrived)
+		orderRoutes.POST("/accept-cash-payment", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("courier"), assignedCourier, courier_controllers.AcceptCashPayment)
+		orderRoutes.POST("/:id/proofs/:stage", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("courier"), middlewares.Authorize(middlewares.Owner(middlewares.OrderCourier(middlewares.Param("id")))), courier_controllers.UploadProof)
 		orderRoutes.POST("/order-delivery", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("courier"), courier_controllers.OrderDelivery)
+		orderRoutes.POST("/:id/delivered", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("courier"), middlewares.Authorize(middlewares.Owner(middlewares.OrderCourier(middlewares.Param("id")))), courier_controllers.ConfirmDelivery)
 
 		//Admin
 		orderRoutes.POST("/order-complete", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), admin_controllers.OrderComplete)
+		orderRoutes.GET("/overdue", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), admin_controllers.GetOverdueOrders)
+		orderRoutes.GET("/garment-mismatches", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), controllers.GetGarmentMismatches)
+		orderRoutes.GET("/stage-summary", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), admin_controllers.GetStageSummary)
+		orderRoutes.POST("/:id/stage", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), admin_controllers.AdvanceOrderStage)
+		orderRoutes.POST("/:id/refunds", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), admin_controllers.CreateRefund)
+		orderRoutes.POST("/:id/delivered/override", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), admin_controllers.OverrideDelivery)
+	}
+
+	paymentRoutes := router.Group("api/payments")
+	{
+		// Dipanggil oleh payment provider, diverifikasi dengan signature bukan JWT
+		paymentRoutes.POST("/webhook/:provider", controllers.PaymentWebhook)
+		paymentRoutes.GET("/reconciliation", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), admin_controllers.GetReconciliationReport)
+		paymentRoutes.POST("/reconciliation", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), admin_controllers.GenerateReconciliationReport)
 	}
 
 	serviceRoutes := router.Group("api/services")
@@ -74,18 +149,67 @@ func SetupRoutes(router *gin.Engine) {
 		serviceController := &admin_controllers.ServiceController{}
 		serviceRoutes.GET("/", serviceController.GetServices)
 		serviceRoutes.GET("/:id", serviceController.GetServiceByID)
-		serviceRoutes.POST("/", serviceController.CreateService)
-		serviceRoutes.PUT("/:id", serviceController.UpdateService)
-		serviceRoutes.DELETE("/:id", serviceController.DeleteService)
+		serviceRoutes.GET("/:id/history", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), serviceController.GetServicePriceHistory)
+		serviceRoutes.POST("/", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), serviceController.CreateService)
+		serviceRoutes.PUT("/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), serviceController.UpdateService)
+		serviceRoutes.DELETE("/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), serviceController.DeleteService)
+		serviceRoutes.POST("/:id/addons", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), serviceController.CreateServiceAddon)
+		serviceRoutes.DELETE("/:id/addons/:addon_id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), serviceController.DeleteServiceAddon)
 		serviceRoutes.GET("/category/:category", serviceController.GetServiceByCategory)
 	}
 
+	promoRoutes := router.Group("api/promos")
+	{
+		promoController := &admin_controllers.PromoController{}
+		promoRoutes.GET("/", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), promoController.GetPromos)
+		promoRoutes.POST("/", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), promoController.CreatePromo)
+		promoRoutes.PUT("/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), promoController.UpdatePromo)
+		promoRoutes.DELETE("/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), promoController.DeletePromo)
+	}
+
+	slotRoutes := router.Group("api/slots")
+	{
+		slotController := &admin_controllers.TimeSlotController{}
+		slotRoutes.GET("/", middlewares.AuthMiddleware(), slotController.GetTimeSlots)
+		slotRoutes.POST("/", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), slotController.CreateTimeSlot)
+		slotRoutes.PUT("/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), slotController.UpdateTimeSlot)
+		slotRoutes.DELETE("/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), slotController.DeleteTimeSlot)
+	}
+
+	serviceAreaRoutes := router.Group("api/service-areas")
+	{
+		serviceAreaController := &admin_controllers.ServiceAreaController{}
+		serviceAreaRoutes.GET("/", serviceAreaController.GetServiceAreas)
+		serviceAreaRoutes.POST("/", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), serviceAreaController.CreateServiceArea)
+		serviceAreaRoutes.PUT("/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), serviceAreaController.UpdateServiceArea)
+		serviceAreaRoutes.DELETE("/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), serviceAreaController.DeleteServiceArea)
+	}
+
+	stageRoutes := router.Group("api/processing-stages")
+	{
+		stageController := &admin_controllers.ProcessingStageController{}
+		stageRoutes.GET("/", stageController.GetProcessingStages)
+		stageRoutes.POST("/", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), stageController.CreateProcessingStage)
+		stageRoutes.PUT("/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), stageController.UpdateProcessingStage)
+		stageRoutes.DELETE("/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), stageController.DeleteProcessingStage)
+	}
+
+	deliveryZoneRoutes := router.Group("api/delivery-zones")
+	{
+		deliveryZoneController := &admin_controllers.DeliveryZoneController{}
+		deliveryZoneRoutes.GET("/", deliveryZoneController.GetDeliveryZones)
+		deliveryZoneRoutes.POST("/", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), deliveryZoneController.CreateDeliveryZone)
+		deliveryZoneRoutes.PUT("/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), deliveryZoneController.UpdateDeliveryZone)
+		deliveryZoneRoutes.DELETE("/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), deliveryZoneController.DeleteDeliveryZone)
+	}
+
 	addressRoutes := router.Group("api/addresses")
 	{
 		addressController := &customer_controller.AddressController{}
 		addressRoutes.POST("/", middlewares.AuthMiddleware(), addressController.CreateAddress)
-		addressRoutes.GET("/user/:user_id", middlewares.AuthMiddleware(), addressController.GetAddressesByUserID)
-		addressRoutes.PUT("/:id", middlewares.AuthMiddleware(), addressController.UpdateAddress)
-		addressRoutes.DELETE("/:id", middlewares.AuthMiddleware(), addressController.DeleteAddress)
+		addressOwner := middlewares.OwnerOrAdmin(middlewares.AddressOwner(middlewares.Param("id")))
+		addressRoutes.GET("/user/:user_id", middlewares.AuthMiddleware(), middlewares.OwnerOrAdmin(middlewares.User(middlewares.Param("user_id"))), addressController.GetAddressesByUserID)
+		addressRoutes.PUT("/:id", middlewares.AuthMiddleware(), addressOwner, addressController.UpdateAddress)
+		addressRoutes.DELETE("/:id", middlewares.AuthMiddleware(), addressOwner, addressController.DeleteAddress)
 	}
 }
@@ -0,0 +1,171 @@
+package scheduling
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"gorm.io/gorm"
+)
+
+// WorkingHours describes when the shop processes laundry. Processing time is
+// only counted while the shop is open.
+type WorkingHours struct {
+	Open           time.Duration // since midnight
+	Close          time.Duration // since midnight
+	ClosedWeekdays map[time.Weekday]bool
+	ClosedDates    map[string]bool // YYYY-MM-DD
+	// DeliveryHours is the working time between laundry being ready and
+	// being delivered when the order has no delivery slot
+	DeliveryHours int
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+	"minggu": time.Sunday, "senin": time.Monday, "selasa": time.Tuesday, "rabu": time.Wednesday,
+	"kamis": time.Thursday, "jumat": time.Friday, "sabtu": time.Saturday,
+}
+
+// WorkingHoursFromEnv reads SHOP_OPEN_AT and SHOP_CLOSE_AT (HH:MM, default
+// 08:00 to 20:00), SHOP_CLOSED_DAYS (comma separated weekdays or YYYY-MM-DD
+// dates, e.g. "sunday,2026-12-25") and DELIVERY_HOURS (default 3).
+func WorkingHoursFromEnv() WorkingHours {
+	hours := WorkingHours{
+		Open:           clockFromEnv("SHOP_OPEN_AT", 8*time.Hour),
+		Close:          clockFromEnv("SHOP_CLOSE_AT", 20*time.Hour),
+		ClosedWeekdays: map[time.Weekday]bool{},
+		ClosedDates:    map[string]bool{},
+		DeliveryHours:  3,
+	}
+	if hours.Close <= hours.Open {
+		log.Printf("scheduling: SHOP_CLOSE_AT must be after SHOP_OPEN_AT, using 08:00 to 20:00")
+		hours.Open, hours.Close = 8*time.Hour, 20*time.Hour
+	}
+
+	for _, day := range strings.Split(os.Getenv("SHOP_CLOSED_DAYS"), ",") {
+		day = strings.ToLower(strings.TrimSpace(day))
+		if day == "" {
+			continue
+		}
+		if weekday, ok := weekdays[day]; ok {
+			hours.ClosedWeekdays[weekday] = true
+		} else if _, err := time.Parse(models.SlotDateLayout, day); err == nil {
+			hours.ClosedDates[day] = true
+		} else {
+			log.Printf("scheduling: ignoring invalid closed day %q", day)
+		}
+	}
+
+	if value := os.Getenv("DELIVERY_HOURS"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed >= 0 {
+			hours.DeliveryHours = parsed
+		} else {
+			log.Printf("scheduling: invalid DELIVERY_HOURS %q, using %d", value, hours.DeliveryHours)
+		}
+	}
+	return hours
+}
+
+func clockFromEnv(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	clock, err := time.Parse(models.SlotTimeLayout, value)
+	if err != nil {
+		log.Printf("scheduling: invalid %s %q", key, value)
+		return fallback
+	}
+	return time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute
+}
+
+// IsOpenOn reports whether the shop opens on the day of t
+func (w WorkingHours) IsOpenOn(t time.Time) bool {
+	return !w.ClosedWeekdays[t.Weekday()] && !w.ClosedDates[t.Format(models.SlotDateLayout)]
+}
+
+// Add returns the moment hours of working time after t
+func (w WorkingHours) Add(t time.Time, hours int) time.Time {
+	remaining := time.Duration(hours) * time.Hour
+	// Batas hari agar konfigurasi yang menutup semua hari tidak membuat loop tanpa akhir
+	for i := 0; i < 3650; i++ {
+		midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+		opening, closing := midnight.Add(w.Open), midnight.Add(w.Close)
+		if !w.IsOpenOn(t) || !t.Before(closing) {
+			t = midnight.AddDate(0, 0, 1).Add(w.Open)
+			continue
+		}
+		if t.Before(opening) {
+			t = opening
+		}
+		available := closing.Sub(t)
+		if remaining <= available {
+			return t.Add(remaining)
+		}
+		remaining -= available
+		t = midnight.AddDate(0, 0, 1).Add(w.Open)
+	}
+	return t
+}
+
+// ProcessingHours is the time an order needs in the shop: the slowest of its
+// lines, where an add-on with its own time (such as express) replaces the
+// time of the service it was chosen for.
+func ProcessingHours(db *gorm.DB, order *models.Order) (int, error) {
+	items := order.Items
+	if len(items) == 0 {
+		items = []models.OrderItem{order.LegacyItem()}
+	}
+
+	longest := 0
+	for _, item := range items {
+		var service models.Service
+		if err := db.Unscoped().Select("id", "time").First(&service, item.ServiceID).Error; err != nil {
+			return 0, err
+		}
+		hours := service.Time
+		for _, chosen := range item.Addons {
+			var addon models.ServiceAddon
+			if err := db.Unscoped().Select("id", "time").First(&addon, chosen.ServiceAddonID).Error; err != nil {
+				return 0, err
+			}
+			if addon.Time > 0 && addon.Time < hours {
+				hours = addon.Time
+			}
+		}
+		if hours > longest {
+			longest = hours
+		}
+	}
+	return longest, nil
+}
+
+// Estimate sets when an order picked up at pickedUpAt should be ready and
+// delivered. A delivery slot ending later than that becomes the delivery
+// estimate. The caller saves the order.
+func Estimate(db *gorm.DB, order *models.Order, pickedUpAt time.Time, hours WorkingHours) error {
+	processing, err := ProcessingHours(db, order)
+	if err != nil {
+		return err
+	}
+
+	readyAt := hours.Add(pickedUpAt, processing)
+	deliveryAt := hours.Add(readyAt, hours.DeliveryHours)
+	if order.DeliverySlotID != nil {
+		var slot models.TimeSlot
+		if err := db.First(&slot, *order.DeliverySlotID).Error; err != nil {
+			return err
+		}
+		if end, err := slot.End(); err == nil && end.After(deliveryAt) {
+			deliveryAt = end
+		}
+	}
+
+	order.EstimatedReadyAt = &readyAt
+	order.EstimatedDeliveryAt = &deliveryAt
+	return nil
+}
@@ -0,0 +1,77 @@
+package scheduling
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrSlotFull is returned when a slot has no capacity left
+	ErrSlotFull = errors.New("time slot is full")
+	// ErrSlotUnavailable is returned for slots that cannot be booked for an
+	// order: unknown, of the wrong kind, for another area or already started
+	ErrSlotUnavailable = errors.New("time slot is not available")
+)
+
+// IsSlotError reports whether err is caused by the chosen slot
+func IsSlotError(err error) bool {
+	return errors.Is(err, ErrSlotFull) || errors.Is(err, ErrSlotUnavailable)
+}
+
+// ServesArea reports whether slot may be booked for an address in area
+func ServesArea(slot *models.TimeSlot, area string) bool {
+	return slot.Area == "" || strings.EqualFold(slot.Area, area)
+}
+
+// Book reserves one place in a slot of kind for an address in area. The
+// capacity check and the reservation are a single conditional update, so two
+// customers cannot take the last place at the same time.
+func Book(tx *gorm.DB, slotID uint, kind, area string, now time.Time) (*models.TimeSlot, error) {
+	var slot models.TimeSlot
+	if err := tx.First(&slot, slotID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: slot %d not found", ErrSlotUnavailable, slotID)
+		}
+		return nil, err
+	}
+	if slot.Kind != kind {
+		return nil, fmt.Errorf("%w: slot %d is not a %s slot", ErrSlotUnavailable, slotID, kind)
+	}
+	if !ServesArea(&slot, area) {
+		return nil, fmt.Errorf("%w: slot %d does not serve %s", ErrSlotUnavailable, slotID, area)
+	}
+	start, err := slot.Start()
+	if err != nil {
+		return nil, err
+	}
+	if !start.After(now) {
+		return nil, fmt.Errorf("%w: slot %d has already started", ErrSlotUnavailable, slotID)
+	}
+
+	result := tx.Model(&models.TimeSlot{}).
+		Where("id = ? AND booked < capacity", slot.ID).
+		UpdateColumn("booked", gorm.Expr("booked + 1"))
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrSlotFull
+	}
+	slot.Booked++
+	return &slot, nil
+}
+
+// Release gives back the place an order held in a slot
+func Release(tx *gorm.DB, slotID *uint) error {
+	if slotID == nil {
+		return nil
+	}
+	return tx.Model(&models.TimeSlot{}).
+		Where("id = ? AND booked > 0", *slotID).
+		UpdateColumn("booked", gorm.Expr("booked - 1")).Error
+}
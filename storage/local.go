@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalDisk stores files in a directory of the server
+type LocalDisk struct {
+	Root string
+}
+
+// NewLocalDisk creates root when needed and stores files inside it
+func NewLocalDisk(root string) (*LocalDisk, error) {
+	absolute, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(absolute, 0o755); err != nil {
+		return nil, err
+	}
+	return &LocalDisk{Root: absolute}, nil
+}
+
+// path maps key to a file inside Root, refusing keys that escape it
+func (disk *LocalDisk) path(key string) (string, error) {
+	path := filepath.Join(disk.Root, filepath.FromSlash(key))
+	if key == "" || !strings.HasPrefix(path, disk.Root+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+	return path, nil
+}
+
+func (disk *LocalDisk) Save(key string, content io.Reader) error {
+	path, err := disk.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	// Tulis ke file sementara agar file yang setengah jadi tidak pernah terbaca
+	file, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, content); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+func (disk *LocalDisk) Open(key string) (io.ReadCloser, error) {
+	path, err := disk.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+func (disk *LocalDisk) Delete(key string) error {
+	path, err := disk.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
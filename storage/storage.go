@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned by Open for keys that were never stored
+var ErrNotFound = errors.New("file not found")
+
+// Storage keeps uploaded files. Keys are slash separated paths chosen by
+// the caller, such as "orders/12/pickup-3f9a.jpg".
+type Storage interface {
+	Save(key string, content io.Reader) error
+	Open(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+var (
+	mu      sync.RWMutex
+	current Storage
+)
+
+// Use makes storage the one returned by Default
+func Use(storage Storage) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = storage
+}
+
+// Default returns the storage configured with Setup
+func Default() Storage {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// Setup configures the storage selected by STORAGE_DRIVER. Only "local" is
+// built in; it keeps files under UPLOAD_DIR (default "uploads").
+func Setup() error {
+	switch driver := strings.ToLower(os.Getenv("STORAGE_DRIVER")); driver {
+	case "", "local":
+		dir := os.Getenv("UPLOAD_DIR")
+		if dir == "" {
+			dir = "uploads"
+		}
+		disk, err := NewLocalDisk(dir)
+		if err != nil {
+			return fmt.Errorf("storage: %w", err)
+		}
+		log.Printf("storage: saving uploads in %s", disk.Root)
+		Use(disk)
+		return nil
+	default:
+		return fmt.Errorf("storage: unknown STORAGE_DRIVER %q", driver)
+	}
+}
+
+// NewKey returns a unique key under prefix ending with ext, e.g. ".jpg"
+func NewKey(prefix, ext string) string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%s-%d%s", prefix, time.Now().UnixNano(), ext)
+	}
+	return fmt.Sprintf("%s-%s%s", prefix, hex.EncodeToString(buf), ext)
+}
@@ -0,0 +1,134 @@
+package tracking
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/raihansyahrin/backend_laundry_app.git/geo"
+	"github.com/raihansyahrin/backend_laundry_app.git/models"
+	"gorm.io/gorm"
+)
+
+// Defaults used when the environment does not configure tracking
+const (
+	DefaultRetention = 2 * time.Hour
+	DefaultSpeedKmh  = 20.0
+)
+
+// TrackedStatuses are the statuses in which a courier is travelling to the
+// order's address and the customer can follow them
+var TrackedStatuses = []models.OrderStatus{
+	models.OrderStatusCourierOnTheWay,
+	models.OrderStatusDelivering,
+}
+
+// Retention returns LOCATION_RETENTION, how long positions are kept
+func Retention() time.Duration {
+	value := os.Getenv("LOCATION_RETENTION")
+	if value == "" {
+		return DefaultRetention
+	}
+	retention, err := time.ParseDuration(value)
+	if err != nil || retention <= 0 {
+		log.Printf("tracking: invalid LOCATION_RETENTION %q, using %s", value, DefaultRetention)
+		return DefaultRetention
+	}
+	return retention
+}
+
+// SpeedKmh returns COURIER_SPEED_KMH, the average speed used for ETAs
+func SpeedKmh() float64 {
+	value := os.Getenv("COURIER_SPEED_KMH")
+	if value == "" {
+		return DefaultSpeedKmh
+	}
+	speed, err := strconv.ParseFloat(value, 64)
+	if err != nil || speed <= 0 {
+		log.Printf("tracking: invalid COURIER_SPEED_KMH %q, using %.0f", value, DefaultSpeedKmh)
+		return DefaultSpeedKmh
+	}
+	return speed
+}
+
+// Record stores a position of a courier. Positions older than the retention
+// are removed by StartPurger.
+func Record(db *gorm.DB, courierID uint, point geo.Point, accuracy float64, now time.Time) (*models.CourierLocation, error) {
+	location := models.CourierLocation{
+		CourierID:  courierID,
+		Latitude:   point.Lat,
+		Longitude:  point.Lng,
+		Accuracy:   accuracy,
+		RecordedAt: now,
+	}
+	if err := db.Create(&location).Error; err != nil {
+		return nil, err
+	}
+	return &location, nil
+}
+
+// Purge removes the positions of all couriers older than the retention,
+// including couriers that stopped sending them
+func Purge(db *gorm.DB, now time.Time) (int64, error) {
+	result := db.Where("recorded_at < ?", now.Add(-Retention())).Delete(&models.CourierLocation{})
+	return result.RowsAffected, result.Error
+}
+
+// StartPurger periodically purges old positions until ctx is done
+func StartPurger(ctx context.Context, db *gorm.DB) {
+	go func() {
+		ticker := time.NewTicker(10 * time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := Purge(db, time.Now()); err != nil {
+					log.Printf("tracking: failed to purge old courier locations: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Latest returns the last position of a courier within the retention that
+// was recorded at or after since, or nil
+func Latest(db *gorm.DB, courierID uint, since, now time.Time) (*models.CourierLocation, error) {
+	if cutoff := now.Add(-Retention()); since.Before(cutoff) {
+		since = cutoff
+	}
+	var location models.CourierLocation
+	err := db.Where("courier_id = ? AND recorded_at >= ?", courierID, since).
+		Order("recorded_at desc").First(&location).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &location, nil
+}
+
+// TrackedSince returns when order entered its current status, so positions
+// the courier sent for earlier jobs are not shown to this order's customer
+func TrackedSince(db *gorm.DB, order models.Order) (time.Time, error) {
+	var event models.OrderStatusEvent
+	err := db.Where("order_id = ? AND to_status = ?", order.ID, order.Status).
+		Order("created_at desc, id desc").Limit(1).Find(&event).Error
+	if err != nil {
+		return time.Time{}, err
+	}
+	if event.ID == 0 {
+		return order.UpdatedAt, nil
+	}
+	return event.CreatedAt, nil
+}
+
+// ETA is the straight-line travel time over distanceKm at the configured speed
+func ETA(distanceKm float64) time.Duration {
+	return time.Duration(distanceKm / SpeedKmh() * float64(time.Hour))
+}
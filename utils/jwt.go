@@ -1,6 +1,9 @@
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"os"
 	"time"
@@ -10,20 +13,29 @@ import (
 
 var jwtKey = []byte(os.Getenv("JWT_SECRET"))
 
+const (
+	// AccessTokenTTL is how long an access token is accepted by AuthMiddleware
+	AccessTokenTTL = 15 * time.Minute
+	// RefreshTokenTTL is how long a session can stay idle before the user has to log in again
+	RefreshTokenTTL = 30 * 24 * time.Hour
+)
+
 type Claims struct {
-	UserID uint   `json:"user_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
+	UserID    uint   `json:"user_id"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	SessionID uint   `json:"sid"`
 	jwt.StandardClaims
 }
 
-// GenerateJWT generates a JWT token
-func GenerateJWT(userID uint, email string, role string) (string, error) {
-	expirationTime := time.Now().Add(24 * time.Hour)
+// GenerateJWT generates a short-lived access token bound to a session
+func GenerateJWT(userID uint, email string, role string, sessionID uint) (string, error) {
+	expirationTime := time.Now().Add(AccessTokenTTL)
 	claims := &Claims{
-		UserID: userID,
-		Email:  email,
-		Role:   role,
+		UserID:    userID,
+		Email:     email,
+		Role:      role,
+		SessionID: sessionID,
 		StandardClaims: jwt.StandardClaims{
 			ExpiresAt: expirationTime.Unix(),
 		},
@@ -53,3 +65,18 @@ func ValidateJWT(tokenString string) (*Claims, error) {
 
 	return claims, nil
 }
+
+// GenerateOpaqueToken returns a random URL-safe token, used for refresh tokens
+func GenerateOpaqueToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// HashToken hashes an opaque token so it can be stored and looked up safely
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}